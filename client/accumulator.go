@@ -0,0 +1,204 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kingfs/godify/models"
+)
+
+// ErrIncompleteStream 流在收到message_end之前结束
+var ErrIncompleteStream = stderrors.New("stream ended before message_end")
+
+// AccumulatedResponse 由流式事件重组的完整响应
+//
+// 内嵌的GenerateResponse与阻塞模式返回的结构一致，其余字段为类型化的附加信息。
+type AccumulatedResponse struct {
+	models.GenerateResponse
+	AgentThoughts      []models.AgentThought      `json:"agent_thoughts,omitempty"`
+	Files              []models.MessageFile       `json:"files,omitempty"`
+	Usage              *models.Usage              `json:"usage,omitempty"`
+	RetrieverResources []models.RetrieverResource `json:"retriever_resources,omitempty"`
+}
+
+// StreamAccumulator 将流式聊天/补全事件重组为完整响应的SSE处理器
+//
+// message与agent_message的增量文本依次拼接，message_replace会替换已有文本。
+// 设置Next后，事件在累积之后会继续转发给Next，便于同时渲染增量输出。
+type StreamAccumulator struct {
+	Next SSEHandler
+
+	mu       sync.Mutex
+	answer   strings.Builder
+	result   AccumulatedResponse
+	thoughts map[string]int
+	done     bool
+	err      error
+}
+
+// NewStreamAccumulator 创建流式累加器
+func NewStreamAccumulator(next SSEHandler) *StreamAccumulator {
+	return &StreamAccumulator{Next: next}
+}
+
+// OnEvent 处理SSE事件
+func (a *StreamAccumulator) OnEvent(event *SSEEvent) error {
+	if event.Data != "" {
+		typed, err := ParseStreamEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := a.apply(typed, event); err != nil {
+			return err
+		}
+	}
+
+	if a.Next != nil {
+		return a.Next.OnEvent(event)
+	}
+	return nil
+}
+
+// apply 将事件合并到累积结果中
+func (a *StreamAccumulator) apply(event models.StreamEvent, raw *SSEEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch e := event.(type) {
+	case *models.MessageEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.answer.WriteString(e.Answer)
+	case *models.AgentMessageEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.answer.WriteString(e.Answer)
+	case *models.MessageReplaceEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.answer.Reset()
+		a.answer.WriteString(e.Answer)
+	case *models.AgentThoughtEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.applyAgentThought(e)
+	case *models.MessageFileEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.result.Files = append(a.result.Files, models.MessageFile{
+			ID:        e.ID,
+			Type:      e.Type,
+			URL:       e.URL,
+			BelongsTo: e.BelongsTo,
+		})
+	case *models.MessageEndEvent:
+		a.applyBase(&e.StreamEventBase)
+		a.result.Usage = e.Metadata.Usage
+		a.result.RetrieverResources = e.Metadata.RetrieverResources
+
+		// 保留原始metadata，与阻塞模式的Metadata字段保持一致
+		var end struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		if err := json.Unmarshal([]byte(raw.Data), &end); err == nil {
+			a.result.Metadata = end.Metadata
+		}
+		a.done = true
+	case *models.ErrorEvent:
+		a.err = StreamErrorToAPIError(e)
+		return a.err
+	}
+
+	return nil
+}
+
+// applyBase 记录公共字段，以首次出现的非空值为准
+func (a *StreamAccumulator) applyBase(base *models.StreamEventBase) {
+	if a.result.TaskID == "" {
+		a.result.TaskID = base.TaskID
+	}
+	if a.result.MessageID == "" {
+		a.result.MessageID = base.MessageID
+		a.result.ID = base.MessageID
+	}
+	if a.result.ConversationID == "" {
+		a.result.ConversationID = base.ConversationID
+	}
+	if a.result.CreatedAt == 0 {
+		a.result.CreatedAt = base.CreatedAt
+	}
+}
+
+// applyAgentThought 同一思考步骤会多次推送，按ID更新
+func (a *StreamAccumulator) applyAgentThought(e *models.AgentThoughtEvent) {
+	thought := models.AgentThought{
+		ID:         e.ID,
+		MessageID:  e.MessageID,
+		Position:   e.Position,
+		Thought:    e.Thought,
+		Tool:       e.Tool,
+		ToolOutput: e.Observation,
+	}
+	if e.CreatedAt > 0 {
+		thought.CreatedAt = models.UnixTime{Time: time.Unix(e.CreatedAt, 0)}
+	}
+	if e.ToolInput != "" {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(e.ToolInput), &input); err == nil {
+			thought.ToolInput = input
+		}
+	}
+	for _, fileID := range e.MessageFiles {
+		thought.MessageFiles = append(thought.MessageFiles, models.MessageFile{ID: fileID})
+	}
+
+	if a.thoughts == nil {
+		a.thoughts = make(map[string]int)
+	}
+	if i, ok := a.thoughts[e.ID]; ok {
+		a.result.AgentThoughts[i] = thought
+		return
+	}
+	a.thoughts[e.ID] = len(a.result.AgentThoughts)
+	a.result.AgentThoughts = append(a.result.AgentThoughts, thought)
+}
+
+// OnError 处理错误
+func (a *StreamAccumulator) OnError(err error) {
+	a.mu.Lock()
+	if a.err == nil {
+		a.err = err
+	}
+	a.mu.Unlock()
+
+	if a.Next != nil {
+		a.Next.OnError(err)
+	}
+}
+
+// OnComplete 处理完成
+func (a *StreamAccumulator) OnComplete() {
+	if a.Next != nil {
+		a.Next.OnComplete()
+	}
+}
+
+// Result 返回累积的完整响应
+//
+// 流中出现错误时返回该错误；未收到message_end时返回已累积的部分结果和ErrIncompleteStream。
+func (a *StreamAccumulator) Result() (*AccumulatedResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := a.result
+	result.Event = string(models.StreamEventMessage)
+	result.Answer = a.answer.String()
+	result.AgentThoughts = append([]models.AgentThought(nil), a.result.AgentThoughts...)
+	result.Files = append([]models.MessageFile(nil), a.result.Files...)
+
+	if a.err != nil {
+		return &result, a.err
+	}
+	if !a.done {
+		return &result, ErrIncompleteStream
+	}
+	return &result, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		base := `"task_id": "task-1", "message_id": "msg-1", "conversation_id": "conv-1", "created_at": 1700000000`
+		fmt.Fprintf(w, "data: {\"event\": \"agent_thought\", \"id\": \"th-1\", %s, \"thought\": \"\", \"tool\": \"search\", \"tool_input\": \"{\\\"q\\\": \\\"go\\\"}\"}\n\n", base)
+		fmt.Fprintf(w, "data: {\"event\": \"agent_message\", %s, \"answer\": \"Bad\"}\n\n", base)
+		fmt.Fprintf(w, "data: {\"event\": \"message_replace\", %s, \"answer\": \"Go\"}\n\n", base)
+		fmt.Fprintf(w, "data: {\"event\": \"agent_message\", %s, \"answer\": \" is fun\"}\n\n", base)
+		fmt.Fprintf(w, "data: {\"event\": \"agent_thought\", \"id\": \"th-1\", %s, \"thought\": \"done\", \"observation\": \"results\", \"tool\": \"search\"}\n\n", base)
+		fmt.Fprintf(w, "data: {\"event\": \"message_end\", %s, \"metadata\": {\"usage\": {\"total_tokens\": 21}, \"retriever_resources\": [{\"position\": 1, \"segment_id\": \"seg-1\"}]}}\n\n", base)
+	}))
+	defer server.Close()
+
+	acc := NewStreamAccumulator(nil)
+	c := newTestBaseClient(server.URL)
+	if err := c.StreamResponse(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}, acc); err != nil {
+		t.Fatalf("StreamResponse failed: %v", err)
+	}
+
+	result, err := acc.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+
+	if result.Answer != "Go is fun" {
+		t.Errorf("Expected answer 'Go is fun', got %q", result.Answer)
+	}
+
+	if result.MessageID != "msg-1" || result.ConversationID != "conv-1" || result.TaskID != "task-1" {
+		t.Errorf("Unexpected identifiers: %+v", result.GenerateResponse)
+	}
+
+	if result.Usage == nil || result.Usage.TotalTokens != 21 {
+		t.Errorf("Expected usage total tokens 21, got %+v", result.Usage)
+	}
+
+	if len(result.RetrieverResources) != 1 {
+		t.Errorf("Expected 1 retriever resource, got %d", len(result.RetrieverResources))
+	}
+
+	if _, ok := result.Metadata["usage"]; !ok {
+		t.Error("Expected metadata to contain usage")
+	}
+
+	if len(result.AgentThoughts) != 1 {
+		t.Fatalf("Expected 1 agent thought, got %d", len(result.AgentThoughts))
+	}
+
+	if result.AgentThoughts[0].Thought != "done" || result.AgentThoughts[0].ToolOutput != "results" {
+		t.Errorf("Expected agent thought to be updated in place, got %+v", result.AgentThoughts[0])
+	}
+}
+
+func TestStreamAccumulatorIncomplete(t *testing.T) {
+	acc := NewStreamAccumulator(nil)
+	if err := acc.OnEvent(&SSEEvent{Data: `{"event": "message", "answer": "partial"}`}); err != nil {
+		t.Fatalf("OnEvent failed: %v", err)
+	}
+
+	result, err := acc.Result()
+	if !stderrors.Is(err, ErrIncompleteStream) {
+		t.Errorf("Expected ErrIncompleteStream, got %v", err)
+	}
+
+	if result.Answer != "partial" {
+		t.Errorf("Expected partial answer, got %q", result.Answer)
+	}
+}
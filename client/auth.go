@@ -0,0 +1,167 @@
+package client
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Authenticator 为请求设置认证信息
+//
+// 在构建http.Request时调用，可通过req.Context()获取请求的上下文。
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// BearerAuth 使用API Key的Bearer认证，用于Service API与Dataset API
+type BearerAuth struct {
+	Token string
+}
+
+// Authenticate 设置Authorization头
+func (a *BearerAuth) Authenticate(req *http.Request) error {
+	if a.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	}
+	return nil
+}
+
+// ConsoleAuth 控制台Cookie认证
+//
+// 以Cookie发送access_token等会话信息，存在csrf_token时同时设置X-CSRF-Token头。
+type ConsoleAuth struct {
+	mu      sync.RWMutex
+	cookies map[string]string
+}
+
+// NewConsoleAuth 创建控制台认证，accessToken为空时不设置access_token
+func NewConsoleAuth(accessToken string) *ConsoleAuth {
+	a := &ConsoleAuth{cookies: make(map[string]string)}
+	if accessToken != "" {
+		a.cookies["access_token"] = accessToken
+	}
+	return a
+}
+
+// SetAccessToken 设置access_token
+func (a *ConsoleAuth) SetAccessToken(token string) {
+	a.SetCookies(map[string]string{"access_token": token})
+}
+
+// SetCookies 合并设置cookies
+func (a *ConsoleAuth) SetCookies(cookies map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, v := range cookies {
+		a.cookies[k] = v
+	}
+}
+
+// Clone 复制认证信息，修改副本不影响原认证
+func (a *ConsoleAuth) Clone() *ConsoleAuth {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	clone := &ConsoleAuth{cookies: make(map[string]string, len(a.cookies))}
+	for k, v := range a.cookies {
+		clone.cookies[k] = v
+	}
+	return clone
+}
+
+// Cookie 获取指定cookie的值
+func (a *ConsoleAuth) Cookie(name string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cookies[name]
+}
+
+// Authenticate 设置Cookie与X-CSRF-Token头
+func (a *ConsoleAuth) Authenticate(req *http.Request) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	addCookies(req, a.cookies)
+	if csrf := a.cookies["csrf_token"]; csrf != "" {
+		req.Header.Set("X-CSRF-Token", csrf)
+	}
+	return nil
+}
+
+// PassportAuth Web API的passport认证
+//
+// 同时发送X-App-Code与passport，passport以Authorization Bearer与X-App-Passport两种方式携带，
+// 兼容不同版本的Dify。
+type PassportAuth struct {
+	mu       sync.RWMutex
+	appCode  string
+	passport string
+}
+
+// NewPassportAuth 创建passport认证
+func NewPassportAuth(appCode string) *PassportAuth {
+	return &PassportAuth{appCode: appCode}
+}
+
+// SetAppCode 设置应用代码
+func (a *PassportAuth) SetAppCode(appCode string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.appCode = appCode
+}
+
+// AppCode 获取应用代码
+func (a *PassportAuth) AppCode() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.appCode
+}
+
+// SetPassport 设置passport
+func (a *PassportAuth) SetPassport(passport string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.passport = passport
+}
+
+// Passport 获取passport
+func (a *PassportAuth) Passport() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.passport
+}
+
+// Clone 复制认证信息，修改副本不影响原认证
+func (a *PassportAuth) Clone() *PassportAuth {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return &PassportAuth{appCode: a.appCode, passport: a.passport}
+}
+
+// Authenticate 设置X-App-Code与passport头，尚未获取passport时只设置X-App-Code
+func (a *PassportAuth) Authenticate(req *http.Request) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.appCode != "" {
+		req.Header.Set("X-App-Code", a.appCode)
+	}
+	if a.passport != "" {
+		req.Header.Set("Authorization", "Bearer "+a.passport)
+		req.Header.Set("X-App-Passport", a.passport)
+	}
+	return nil
+}
+
+// addCookies 按名称顺序添加cookies，保证请求头稳定
+func addCookies(req *http.Request, cookies map[string]string) {
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		req.AddCookie(&http.Cookie{Name: name, Value: cookies[name]})
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kingfs/godify/config"
@@ -34,42 +36,96 @@ type ClientConfig struct {
 	Token      string
 	HTTPClient *http.Client
 	Timeout    time.Duration
+	// MaxRetries 为0时默认重试3次，不重试可设为负数或使用WithMaxRetries(0)
 	MaxRetries int
 	SkipTLS    bool
 
+	// 重试策略，为nil时使用基于MaxRetries的指数退避策略
+	RetryPolicy RetryPolicy
+
+	// 请求中间件，按顺序由外向内执行
+	Middlewares []Middleware
+
 	WorkspaceID *string
-	Cookies     map[string]string
+	// Cookies 额外发送的cookies；未设置Authenticator时作为控制台Cookie认证的初始值
+	Cookies map[string]string
+
+	// Authenticator 请求认证方式，为nil时根据Token或Cookies推断
+	Authenticator Authenticator
 
 	// 监控配置
 	Metrics *metrics.Metrics
 
+	// 流式请求断线重连配置，为nil时不重连
+	StreamReconnect *StreamReconnectConfig
+	// SSE单行最大长度，为0时使用DefaultSSEMaxLineSize
+	SSEMaxLineSize int
+
 	// 连接池配置
 	MaxIdleConns        int
 	MaxIdleConnsPerHost int
 	IdleConnTimeout     time.Duration
+
+	// TLSConfig 自定义TLS配置（CA证书、客户端证书等），设置HTTPClient时忽略
+	TLSConfig *tls.Config
+	// Proxy 代理选择函数，如http.ProxyFromEnvironment，设置HTTPClient时忽略
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Logger 日志器，为nil时使用slog.Default()
+	Logger *slog.Logger
+	// UserAgent 请求的User-Agent
+	UserAgent string
+	// Headers 每个请求默认携带的请求头，可被请求中的同名头覆盖
+	Headers map[string]string
 }
 
 // BaseClient 基础HTTP客户端
+//
+// 配置以不可变快照保存，每个请求使用发起时的快照；With*等修改方法以写时复制的方式
+// 替换快照，可与进行中的请求并发调用。
 type BaseClient struct {
-	config     *ClientConfig
+	config atomic.Pointer[ClientConfig]
+	// mu 串行化配置的修改
+	mu         sync.Mutex
 	httpClient *http.Client
-	logger     *slog.Logger
-	metrics    *metrics.Metrics
+	// streamClient 与httpClient共享Transport，但不设置整体超时，
+	// 流式响应的持续时间不可预知，由ctx控制生命周期
+	streamClient *http.Client
+	logger       atomic.Pointer[slog.Logger]
+	metrics      *metrics.Metrics
 }
 
-// NewBaseClient 创建基础客户端
-func NewBaseClient(config *ClientConfig) *BaseClient {
+// NewBaseClient 创建基础客户端，opts在创建前依次应用到config
+func NewBaseClient(config *ClientConfig, opts ...Option) *BaseClient {
+	// 默认值先于opts设置，使WithMaxRetries(0)能够关闭重试
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
 	if config.HTTPClient == nil {
 		// 配置连接池
 		transport := &http.Transport{
+			Proxy:               config.Proxy,
 			MaxIdleConns:        config.MaxIdleConns,
 			MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
 			IdleConnTimeout:     config.IdleConnTimeout,
 		}
+		if config.TLSConfig != nil {
+			transport.TLSClientConfig = config.TLSConfig.Clone()
+		}
 		if config.SkipTLS {
-			transport.TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: true,
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{}
 			}
+			transport.TLSClientConfig.InsecureSkipVerify = true
 		}
 
 		config.HTTPClient = &http.Client{
@@ -78,12 +134,21 @@ func NewBaseClient(config *ClientConfig) *BaseClient {
 		}
 	}
 
-	if config.Timeout == 0 {
-		config.Timeout = 30 * time.Second
+	if config.RetryPolicy == nil {
+		config.RetryPolicy = NewBackoffRetryPolicy(config.MaxRetries)
 	}
 
-	if config.MaxRetries == 0 {
-		config.MaxRetries = 3
+	if config.Authenticator == nil {
+		switch {
+		case config.Token != "":
+			// AuthTypeBearer与AuthTypeAPIKey均以Bearer方式发送
+			config.Authenticator = &BearerAuth{Token: config.Token}
+		case config.Cookies != nil:
+			auth := NewConsoleAuth("")
+			auth.SetCookies(config.Cookies)
+			config.Authenticator = auth
+			config.Cookies = nil
+		}
 	}
 
 	// 设置默认监控
@@ -91,46 +156,164 @@ func NewBaseClient(config *ClientConfig) *BaseClient {
 		config.Metrics = metrics.NewMetrics(false) // 默认关闭监控
 	}
 
-	return &BaseClient{
-		config:     config,
-		httpClient: config.HTTPClient,
-		logger:     slog.Default(),
-		metrics:    config.Metrics,
+	streamClient := *config.HTTPClient
+	streamClient.Timeout = 0
+
+	c := &BaseClient{
+		httpClient:   config.HTTPClient,
+		streamClient: &streamClient,
+		metrics:      config.Metrics,
+	}
+	c.config.Store(cloneConfig(config))
+	if config.Logger != nil {
+		c.logger.Store(config.Logger)
+	} else {
+		c.logger.Store(slog.Default())
+	}
+	return c
+}
+
+// cloneConfig 复制配置，Cookies与Middlewares不与原配置共享
+func cloneConfig(config *ClientConfig) *ClientConfig {
+	clone := *config
+	if config.Cookies != nil {
+		clone.Cookies = make(map[string]string, len(config.Cookies))
+		for k, v := range config.Cookies {
+			clone.Cookies[k] = v
+		}
+	}
+	if config.Headers != nil {
+		clone.Headers = make(map[string]string, len(config.Headers))
+		for k, v := range config.Headers {
+			clone.Headers[k] = v
+		}
+	}
+	clone.Middlewares = append([]Middleware(nil), config.Middlewares...)
+	return &clone
+}
+
+// snapshot 返回当前配置快照，调用方不得修改
+func (c *BaseClient) snapshot() *ClientConfig {
+	return c.config.Load()
+}
+
+// log 返回当前日志器
+func (c *BaseClient) log() *slog.Logger {
+	return c.logger.Load()
+}
+
+// update 以写时复制的方式修改配置
+func (c *BaseClient) update(fn func(config *ClientConfig)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	config := cloneConfig(c.config.Load())
+	fn(config)
+	c.config.Store(config)
+}
+
+// Derive 基于当前配置派生新的客户端，派生客户端与原客户端共享连接池、监控与日志器
+//
+// fn修改的是配置副本，之后对任一客户端的修改互不影响；Authenticator默认共享，
+// WithToken与WithCookies会先复制认证器再修改，需要独立认证状态时也可在fn中替换。
+func (c *BaseClient) Derive(fn func(config *ClientConfig)) *BaseClient {
+	config := cloneConfig(c.snapshot())
+	if fn != nil {
+		fn(config)
+	}
+
+	derived := &BaseClient{
+		httpClient:   c.httpClient,
+		streamClient: c.streamClient,
+		metrics:      c.metrics,
 	}
+	derived.config.Store(config)
+	derived.logger.Store(c.log())
+	return derived
+}
+
+// WithWorkspace 派生使用指定工作空间ID的客户端，原客户端不受影响
+func (c *BaseClient) WithWorkspace(workspaceID string) *BaseClient {
+	return c.Derive(func(config *ClientConfig) {
+		config.WorkspaceID = &workspaceID
+	})
 }
 
 // WithWorkspaceID 设置工作空间ID
 func (c *BaseClient) WithWorkspaceID(workspaceID string) *BaseClient {
-	c.config.WorkspaceID = &workspaceID
+	c.update(func(config *ClientConfig) {
+		config.WorkspaceID = &workspaceID
+	})
 	return c
 }
 
-// WithToken 设置认证token
+// WithToken 设置认证token，按当前认证方式更新access_token、passport或API Key
 func (c *BaseClient) WithToken(token string) *BaseClient {
-	if c.config.Cookies == nil {
-		c.config.Cookies = make(map[string]string)
-	}
-	c.config.Cookies["access_token"] = token
+	c.update(func(config *ClientConfig) {
+		// 认证器可能与派生客户端共享，修改副本而不是原对象
+		switch auth := config.Authenticator.(type) {
+		case *ConsoleAuth:
+			auth = auth.Clone()
+			auth.SetAccessToken(token)
+			config.Authenticator = auth
+		case *PassportAuth:
+			auth = auth.Clone()
+			auth.SetPassport(token)
+			config.Authenticator = auth
+		case *BearerAuth:
+			config.Authenticator = &BearerAuth{Token: token}
+		case nil:
+			config.Authenticator = NewConsoleAuth(token)
+		}
+	})
 	return c
 }
 
 // WithLogger 设置日志器
 func (c *BaseClient) WithLogger(logger *slog.Logger) *BaseClient {
-	c.logger = logger
+	c.logger.Store(logger)
 	return c
 }
 
-// WithCookies 设置cookies
+// WithCookies 设置cookies，使用控制台Cookie认证时合并到认证信息中
 func (c *BaseClient) WithCookies(cookies map[string]string) *BaseClient {
-	if c.config.Cookies == nil {
-		c.config.Cookies = make(map[string]string)
-	}
-	for k, v := range cookies {
-		c.config.Cookies[k] = v
-	}
+	c.update(func(config *ClientConfig) {
+		switch auth := config.Authenticator.(type) {
+		case *ConsoleAuth:
+			auth = auth.Clone()
+			auth.SetCookies(cookies)
+			config.Authenticator = auth
+			return
+		case nil:
+			consoleAuth := NewConsoleAuth("")
+			consoleAuth.SetCookies(cookies)
+			config.Authenticator = consoleAuth
+			return
+		}
+
+		if config.Cookies == nil {
+			config.Cookies = make(map[string]string, len(cookies))
+		}
+		for k, v := range cookies {
+			config.Cookies[k] = v
+		}
+	})
 	return c
 }
 
+// WithAuthenticator 设置认证方式
+func (c *BaseClient) WithAuthenticator(auth Authenticator) *BaseClient {
+	c.update(func(config *ClientConfig) {
+		config.Authenticator = auth
+	})
+	return c
+}
+
+// Authenticator 获取当前的认证方式
+func (c *BaseClient) Authenticator() Authenticator {
+	return c.snapshot().Authenticator
+}
+
 // GetMetrics 获取监控指标
 func (c *BaseClient) GetMetrics() *metrics.Metrics {
 	return c.metrics
@@ -168,8 +351,15 @@ type Request struct {
 	Method  string
 	Path    string
 	Headers map[string]string
-	Query   map[string]string
-	Body    interface{}
+	// Query 查询参数，同名参数可以有多个值
+	Query url.Values
+	// Body 支持string、[]byte、io.Reader，其余类型按JSON编码
+	Body interface{}
+	// ContentLength io.Reader请求体的已知长度，0表示未知（使用分块传输）
+	ContentLength int64
+	// Resumable 流式请求中断后可携带Last-Event-ID安全重连，
+	// 非幂等的流式请求（如POST /chat-messages）需显式设置才会重连
+	Resumable bool
 }
 
 // Response 响应
@@ -178,27 +368,100 @@ type Response struct {
 	Headers    http.Header
 	Body       []byte
 	Cookies    map[string]string // Cookie 名称到值的映射
+	// Stream 流式请求成功时的未读响应体，此时Body为空，由流式处理负责关闭
+	Stream io.ReadCloser
+	// RequestID 请求的X-Request-Id
+	RequestID string
+	// Attempts 得到该响应共发送的次数
+	Attempts int
+	// Method、Path 产生该响应的请求
+	Method string
+	Path   string
+}
+
+// RequestInfo 返回产生该响应的请求信息
+func (r *Response) RequestInfo() errors.RequestInfo {
+	return errors.RequestInfo{Method: r.Method, Path: r.Path, Attempts: r.Attempts, RequestID: r.RequestID}
+}
+
+// DecodeJSON 将响应体解析为JSON，失败时返回 *errors.DecodeError
+func (r *Response) DecodeJSON(result interface{}) error {
+	if err := json.Unmarshal(r.Body, result); err != nil {
+		return errors.NewDecodeError(r.RequestInfo(), r.StatusCode, r.Body, err)
+	}
+	return nil
 }
 
 // Do 执行HTTP请求
 func (c *BaseClient) Do(ctx context.Context, req *Request) (*Response, error) {
 	startTime := time.Now()
+	req = withRequestID(req)
 
-	// 记录请求开始
-	c.logger.DebugContext(ctx, "Starting HTTP request", "method", req.Method, "path", req.Path, "url", c.config.BaseURL+req.Path)
+	response, err := c.chain(c.roundTrip)(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录请求完成
+	duration := time.Since(startTime)
+	c.log().DebugContext(ctx, "HTTP request completed", "status_code", response.StatusCode, "duration_ms", duration.Milliseconds(), "body_size", len(response.Body))
+
+	// 记录监控指标
+	c.metrics.RecordRequest(response.StatusCode < 400, duration)
+
+	// 检查错误响应
+	if response.StatusCode >= 400 {
+		c.log().ErrorContext(ctx, "HTTP request failed", "status_code", response.StatusCode, "body", string(response.Body))
+		return response, c.parseError(ctx, response)
+	}
+
+	return response, nil
+}
+
+// roundTrip 发送请求并读取完整响应体，是中间件链的最内层
+func (c *BaseClient) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	resp, attempts, err := c.send(ctx, c.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			// 记录关闭错误，但不影响主流程
+			c.log().WarnContext(ctx, "Failed to close response body", "error", closeErr)
+		}
+	}()
+
+	// 读取响应体
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, transportError(requestInfo(req, attempts), err)
+	}
+
+	response := newResponse(resp, respBody)
+	response.RequestID = req.Headers[RequestIDHeader]
+	response.Attempts = attempts
+	response.Method, response.Path = req.Method, req.Path
+	return response, nil
+}
+
+// newHTTPRequest 根据Request构建http.Request
+func (c *BaseClient) newHTTPRequest(ctx context.Context, req *Request) (*http.Request, error) {
+	config := c.snapshot()
 
 	// 构建URL
-	u, err := url.Parse(c.config.BaseURL + req.Path)
+	u, err := url.Parse(config.BaseURL + req.Path)
 	if err != nil {
-		c.logger.ErrorContext(ctx, "Failed to parse URL", "error", err)
+		c.log().ErrorContext(ctx, "Failed to parse URL", "error", err)
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// 添加查询参数
 	if req.Query != nil {
 		q := u.Query()
-		for k, v := range req.Query {
-			q.Set(k, v)
+		for k, values := range req.Query {
+			for _, v := range values {
+				q.Add(k, v)
+			}
 		}
 		u.RawQuery = q.Encode()
 	}
@@ -215,6 +478,9 @@ func (c *BaseClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		case []byte:
 			body = bytes.NewReader(v)
 			contentType = "application/octet-stream"
+		case io.Reader:
+			// 流式请求体，Content-Type由调用方通过Headers设置
+			body = v
 		default:
 			jsonData, err := json.Marshal(v)
 			if err != nil {
@@ -231,35 +497,40 @@ func (c *BaseClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if _, ok := req.Body.(io.Reader); ok && req.ContentLength > 0 {
+		httpReq.ContentLength = req.ContentLength
+	}
+
 	// 设置Content-Type
 	if contentType != "" {
 		httpReq.Header.Set("Content-Type", contentType)
 	}
 
-	// // 设置认证头
-	// switch c.config.AuthType {
-	// case AuthTypeBearer:
-	// 	httpReq.Header.Set("Authorization", "Bearer "+c.config.Token)
-	// case AuthTypeAPIKey:
-	// 	httpReq.Header.Set("Authorization", "Bearer "+c.config.Token)
-	// }
-
-	// 设置cookies
-	if c.config.Cookies != nil {
-		// 将c.config.Cookies中的所有键值对拼成string
-		cookieString := ""
-		for k, v := range c.config.Cookies {
-			cookieString += k + "=" + v + "; "
-		}
-		httpReq.Header.Add("Cookie", cookieString)
+	if config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", config.UserAgent)
+	}
+	for k, v := range config.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	// 设置额外的cookies
+	if config.Cookies != nil {
+		addCookies(httpReq, config.Cookies)
 		// csrf_token
-		if c.config.Cookies["csrf_token"] != "" {
-			httpReq.Header.Add("X-CSRF-Token", c.config.Cookies["csrf_token"])
+		if config.Cookies["csrf_token"] != "" {
+			httpReq.Header.Set("X-CSRF-Token", config.Cookies["csrf_token"])
+		}
+	}
+
+	// 设置认证信息
+	if config.Authenticator != nil {
+		if err := config.Authenticator.Authenticate(httpReq); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
 		}
 	}
 
-	if c.config.WorkspaceID != nil {
-		httpReq.Header.Set("X-Workspace-Id", *c.config.WorkspaceID)
+	if config.WorkspaceID != nil {
+		httpReq.Header.Set("X-Workspace-Id", *config.WorkspaceID)
 	}
 
 	// 设置自定义头
@@ -267,40 +538,105 @@ func (c *BaseClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		httpReq.Header.Set(k, v)
 	}
 
+	return httpReq, nil
+}
+
+// send 发送请求（带重试），返回尚未读取的响应，调用方负责关闭响应体
+//
+// 返回实际发送的次数；未得到响应时返回 *errors.NetworkError 或 *errors.TimeoutError。
+func (c *BaseClient) send(ctx context.Context, httpClient *http.Client, req *Request) (*http.Response, int, error) {
+	config := c.snapshot()
+
+	// 记录请求开始
+	c.log().DebugContext(ctx, "Starting HTTP request", "method", req.Method, "path", req.Path, "url", config.BaseURL+req.Path)
+
+	httpReq, err := c.newHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// 执行请求（带重试）
+	startTime := time.Now()
 	var resp *http.Response
-	var lastErr error
-	for i := 0; i <= c.config.MaxRetries; i++ {
-		resp, err = c.httpClient.Do(httpReq)
-		if err == nil && resp.StatusCode < 500 {
+	attempt := 0
+	for ; ; attempt++ {
+		resp, err = httpClient.Do(httpReq)
+		if err == nil && resp.StatusCode < 400 {
+			break
+		}
+		// 流式请求体只能读取一次，无法重放时不进行重试
+		if !replayable(httpReq) {
 			break
 		}
 
-		lastErr = err
-		c.logger.WarnContext(ctx, "Request failed, retrying...", "attempt", i+1, "max_retries", c.config.MaxRetries, "error", err)
+		wait, retry := config.RetryPolicy.ShouldRetry(httpReq, resp, err, attempt, time.Since(startTime))
+		if !retry {
+			break
+		}
 
-		if i < c.config.MaxRetries {
-			time.Sleep(time.Duration(i+1) * time.Second)
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		c.log().WarnContext(ctx, "Request failed, retrying...", "attempt", attempt+1, "status_code", status, "wait", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if resp != nil {
+				drainAndClose(resp.Body)
+			}
+			return nil, attempt + 1, transportError(requestInfo(req, attempt+1), ctx.Err())
+		case <-timer.C:
 		}
-	}
 
-	if lastErr != nil {
-		c.logger.ErrorContext(ctx, "Request failed after all retries", "error", lastErr)
-		return nil, fmt.Errorf("request failed: %w", lastErr)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			// 记录关闭错误，但不影响主流程
-			c.logger.WarnContext(ctx, "Failed to close response body", "error", closeErr)
+		// 释放上一次的响应，使连接可以复用
+		if resp != nil {
+			drainAndClose(resp.Body)
 		}
-	}()
 
-	// 读取响应体
-	respBody, err := io.ReadAll(resp.Body)
+		// 每次重试使用新的请求和请求体，上一次的请求体已被读取
+		httpReq, err = rewindRequest(ctx, httpReq)
+		if err != nil {
+			return nil, attempt + 1, err
+		}
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		c.log().ErrorContext(ctx, "Request failed after all retries", "attempts", attempt+1, "error", err)
+		return nil, attempt + 1, transportError(requestInfo(req, attempt+1), err)
 	}
 
+	return resp, attempt + 1, nil
+}
+
+// replayable 判断请求体是否可以重新发送
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest 复制请求并通过GetBody重建请求体
+func rewindRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	next := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// drainAndClose 读取并关闭响应体，读取量有上限以免下载过大的错误响应
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	_ = body.Close()
+}
+
+// newResponse 从http.Response构建Response
+func newResponse(resp *http.Response, body []byte) *Response {
 	// 提取 cookies（如果有）
 	var cookies map[string]string
 	if respCookies := resp.Cookies(); len(respCookies) > 0 {
@@ -310,27 +646,12 @@ func (c *BaseClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		}
 	}
 
-	response := &Response{
+	return &Response{
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
-		Body:       respBody,
+		Body:       body,
 		Cookies:    cookies,
 	}
-
-	// 记录请求完成
-	duration := time.Since(startTime)
-	c.logger.DebugContext(ctx, "HTTP request completed", "status_code", resp.StatusCode, "duration_ms", duration.Milliseconds(), "body_size", len(respBody))
-
-	// 记录监控指标
-	c.metrics.RecordRequest(resp.StatusCode < 400, duration)
-
-	// 检查错误响应
-	if resp.StatusCode >= 400 {
-		c.logger.ErrorContext(ctx, "HTTP request failed", "status_code", resp.StatusCode, "body", string(respBody))
-		return response, c.parseError(ctx, response)
-	}
-
-	return response, nil
 }
 
 // DoJSON 执行JSON请求并解析响应
@@ -341,9 +662,7 @@ func (c *BaseClient) DoJSON(ctx context.Context, req *Request, result interface{
 	}
 
 	if result != nil && len(resp.Body) > 0 {
-		if err := json.Unmarshal(resp.Body, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
+		return resp.DecodeJSON(result)
 	}
 
 	return nil
@@ -392,7 +711,7 @@ func (c *BaseClient) parseError(ctx context.Context, resp *Response) error {
 	var errResp errors.ErrorResponse
 	if err := json.Unmarshal(resp.Body, &errResp); err != nil {
 		// 如果无法解析为结构化错误，返回通用错误
-		c.logger.WarnContext(ctx, "Failed to parse structured error response", "status_code", resp.StatusCode, "body", string(resp.Body), "error", err)
+		c.log().WarnContext(ctx, "Failed to parse structured error response", "status_code", resp.StatusCode, "body", string(resp.Body), "error", err)
 
 		// 记录错误类型
 		c.metrics.RecordError("parse_error")
@@ -400,18 +719,20 @@ func (c *BaseClient) parseError(ctx context.Context, resp *Response) error {
 		return &errors.APIError{
 			StatusCode: resp.StatusCode,
 			Message:    string(resp.Body),
+			RequestID:  resp.RequestID,
 		}
 	}
 
 	// 记录特定错误类型
 	c.metrics.RecordError(errResp.Code)
 
-	c.logger.ErrorContext(ctx, "API error occurred", "status_code", resp.StatusCode, "error_code", errResp.Code, "message", errResp.Message)
+	c.log().ErrorContext(ctx, "API error occurred", "status_code", resp.StatusCode, "error_code", errResp.Code, "message", errResp.Message)
 
 	return &errors.APIError{
 		StatusCode: resp.StatusCode,
 		Code:       errResp.Code,
 		Message:    errResp.Message,
 		Details:    errResp.Details,
+		RequestID:  resp.RequestID,
 	}
 }
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// echoWorkspaceServer 在响应体中返回请求的X-Workspace-Id
+func echoWorkspaceServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"workspace": %q}`, r.Header.Get("X-Workspace-Id"))
+	}))
+}
+
+func TestConfigMutatorsAreRaceFree(t *testing.T) {
+	server := echoWorkspaceServer()
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL).WithCookies(map[string]string{"locale": "en"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"}); err != nil {
+					t.Errorf("Do failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			c.WithWorkspaceID(fmt.Sprintf("ws-%d", j))
+			c.WithCookies(map[string]string{"locale": fmt.Sprintf("l-%d", j)})
+			c.Use(HeaderMiddleware(map[string]string{"X-Iteration": fmt.Sprint(j)}))
+		}
+	}()
+	wg.Wait()
+}
+
+func TestWithWorkspaceDerivesIndependentClient(t *testing.T) {
+	server := echoWorkspaceServer()
+	defer server.Close()
+
+	var calls int
+	var mu sync.Mutex
+	base := newTestBaseClient(server.URL).WithWorkspaceID("ws-base")
+	base.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return next(ctx, req)
+		}
+	})
+
+	derived := base.WithWorkspace("ws-derived")
+	derived.WithWorkspaceID("ws-derived-2")
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	for i, c := range []*BaseClient{base, derived} {
+		wg.Add(1)
+		go func(i int, c *BaseClient) {
+			defer wg.Done()
+			var result map[string]string
+			if err := c.DoJSON(context.Background(), &Request{Method: "GET", Path: "/apps"}, &result); err != nil {
+				t.Errorf("DoJSON failed: %v", err)
+			}
+			results[i] = result["workspace"]
+		}(i, c)
+	}
+	wg.Wait()
+
+	if results[0] != "ws-base" {
+		t.Errorf("Expected base client to keep workspace 'ws-base', got %s", results[0])
+	}
+	if results[1] != "ws-derived-2" {
+		t.Errorf("Expected derived client workspace 'ws-derived-2', got %s", results[1])
+	}
+	if calls != 2 {
+		t.Errorf("Expected derived client to inherit middlewares, got %d calls", calls)
+	}
+	if derived.httpClient != base.httpClient {
+		t.Error("Expected derived client to share the HTTP transport")
+	}
+}
+
+func TestDerivedCredentialsDoNotAffectParent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		if cookie, err := r.Cookie("access_token"); err == nil {
+			token = cookie.Value
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": %q, "passport": %q}`, token, r.Header.Get("X-App-Passport"))
+	}))
+	defer server.Close()
+
+	send := func(c *BaseClient) map[string]string {
+		var result map[string]string
+		if err := c.DoJSON(context.Background(), &Request{Method: "GET", Path: "/apps"}, &result); err != nil {
+			t.Fatalf("DoJSON failed: %v", err)
+		}
+		return result
+	}
+
+	parent := newTestBaseClient(server.URL).WithAuthenticator(NewConsoleAuth("parent-token"))
+	derived := parent.Derive(nil)
+	derived.WithToken("derived-token")
+	derived.WithCookies(map[string]string{"access_token": "derived-cookie"})
+
+	if token := send(parent)["token"]; token != "parent-token" {
+		t.Errorf("Expected parent to keep 'parent-token', got %s", token)
+	}
+	if token := send(derived)["token"]; token != "derived-cookie" {
+		t.Errorf("Expected derived client to send 'derived-cookie', got %s", token)
+	}
+
+	passport := NewPassportAuth("app")
+	passport.SetPassport("parent-passport")
+	parent = newTestBaseClient(server.URL).WithAuthenticator(passport)
+	parent.Derive(nil).WithToken("derived-passport")
+
+	if got := send(parent)["passport"]; got != "parent-passport" {
+		t.Errorf("Expected parent to keep 'parent-passport', got %s", got)
+	}
+}
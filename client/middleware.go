@@ -0,0 +1,50 @@
+package client
+
+import "context"
+
+// Handler 执行请求并返回响应
+//
+// 非流式请求返回的Response包含完整的Body；流式请求成功时Body为空，
+// 响应体通过Response.Stream提供。状态码>=400的响应同样以Response返回，
+// 由BaseClient在中间件链之外转换为错误。重试发生在链的最内层，
+// 每次逻辑请求中间件只执行一次。
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware 请求中间件，可在调用next前后修改请求和响应
+type Middleware func(next Handler) Handler
+
+// Use 追加中间件，先添加的中间件位于外层
+func (c *BaseClient) Use(middlewares ...Middleware) *BaseClient {
+	c.update(func(config *ClientConfig) {
+		config.Middlewares = append(config.Middlewares, middlewares...)
+	})
+	return c
+}
+
+// chain 将中间件包装在最内层的handler之外
+func (c *BaseClient) chain(handler Handler) Handler {
+	middlewares := c.snapshot().Middlewares
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// HeaderMiddleware 为每个请求添加固定的请求头，不覆盖请求中已设置的同名头
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			merged := make(map[string]string, len(headers)+len(req.Headers))
+			for k, v := range headers {
+				merged[k] = v
+			}
+			for k, v := range req.Headers {
+				merged[k] = v
+			}
+
+			clone := *req
+			clone.Headers = merged
+			return next(ctx, &clone)
+		}
+	}
+}
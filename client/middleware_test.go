@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareOrderAndResponseAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sig := r.Header.Get("X-Signature"); sig != "signed:/apps" {
+			t.Errorf("Expected X-Signature 'signed:/apps', got %s", sig)
+		}
+		if tenant := r.Header.Get("X-Tenant"); tenant != "acme" {
+			t.Errorf("Expected X-Tenant 'acme', got %s", tenant)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": "not_found", "message": "app not found"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, name+":before")
+				resp, err := next(ctx, req)
+				order = append(order, fmt.Sprintf("%s:after:%d", name, resp.StatusCode))
+				return resp, err
+			}
+		}
+	}
+	sign := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			req.Headers["X-Signature"] = "signed:" + req.Path
+			return next(ctx, req)
+		}
+	}
+
+	c := newTestBaseClient(server.URL)
+	c.Use(trace("outer"), HeaderMiddleware(map[string]string{"X-Tenant": "acme"}), trace("inner"), sign)
+
+	_, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"})
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+
+	expected := "[outer:before inner:before inner:after:404 outer:after:404]"
+	if fmt.Sprint(order) != expected {
+		t.Errorf("Expected middleware order %s, got %v", expected, order)
+	}
+}
+
+func TestMiddlewareWrapsStreamingRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Audit") != "1" {
+			t.Error("Expected X-Audit header on streaming request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"event\": \"message\", \"answer\": \"Hi\"}\n\n")
+		fmt.Fprint(w, "data: {\"event\": \"message_end\"}\n\n")
+	}))
+	defer server.Close()
+
+	var streamed bool
+	c := newTestBaseClient(server.URL)
+	c.Use(HeaderMiddleware(map[string]string{"X-Audit": "1"}), func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			resp, err := next(ctx, req)
+			streamed = err == nil && resp.Stream != nil && resp.Body == nil
+			return resp, err
+		}
+	})
+
+	handler := &recordingHandler{}
+	if err := c.StreamResponse(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}, handler); err != nil {
+		t.Fatalf("StreamResponse failed: %v", err)
+	}
+
+	if !streamed {
+		t.Error("Expected middleware to observe an unread response stream")
+	}
+
+	if len(handler.events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(handler.events))
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kingfs/godify/metrics"
+)
+
+// Option 客户端配置选项，用于各API包的NewClient
+type Option func(config *ClientConfig)
+
+// WithHTTPClient 使用自定义的http.Client，此时TLS、代理与连接池配置不生效
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(config *ClientConfig) {
+		config.HTTPClient = httpClient
+	}
+}
+
+// WithTimeout 设置非流式请求的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(config *ClientConfig) {
+		config.Timeout = timeout
+	}
+}
+
+// WithMaxRetries 设置默认重试策略的最大重试次数，0表示不重试
+func WithMaxRetries(maxRetries int) Option {
+	return func(config *ClientConfig) {
+		config.MaxRetries = maxRetries
+	}
+}
+
+// WithRetryPolicy 设置重试策略
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(config *ClientConfig) {
+		config.RetryPolicy = policy
+	}
+}
+
+// WithTLSConfig 使用自定义TLS配置，同时关闭跳过证书校验
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(config *ClientConfig) {
+		config.TLSConfig = tlsConfig
+		config.SkipTLS = false
+	}
+}
+
+// WithRootCAs 使用自定义CA证书校验服务端，同时关闭跳过证书校验
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(config *ClientConfig) {
+		config.TLSConfig = tlsConfigOf(config)
+		config.TLSConfig.RootCAs = pool
+		config.SkipTLS = false
+	}
+}
+
+// WithClientCertificate 设置双向TLS的客户端证书
+func WithClientCertificate(certs ...tls.Certificate) Option {
+	return func(config *ClientConfig) {
+		config.TLSConfig = tlsConfigOf(config)
+		config.TLSConfig.Certificates = append(config.TLSConfig.Certificates, certs...)
+	}
+}
+
+// WithSkipTLS 设置是否跳过服务端证书校验
+func WithSkipTLS(skip bool) Option {
+	return func(config *ClientConfig) {
+		config.SkipTLS = skip
+	}
+}
+
+// WithProxy 设置代理选择函数，如http.ProxyFromEnvironment或http.ProxyURL(u)
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(config *ClientConfig) {
+		config.Proxy = proxy
+	}
+}
+
+// WithLogger 设置日志器
+func WithLogger(logger *slog.Logger) Option {
+	return func(config *ClientConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithMetrics 设置监控指标
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(config *ClientConfig) {
+		config.Metrics = m
+	}
+}
+
+// WithUserAgent 设置User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(config *ClientConfig) {
+		config.UserAgent = userAgent
+	}
+}
+
+// WithHeaders 设置每个请求默认携带的请求头，可多次调用合并
+func WithHeaders(headers map[string]string) Option {
+	return func(config *ClientConfig) {
+		if config.Headers == nil {
+			config.Headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			config.Headers[k] = v
+		}
+	}
+}
+
+// WithMiddlewares 追加请求中间件
+func WithMiddlewares(middlewares ...Middleware) Option {
+	return func(config *ClientConfig) {
+		config.Middlewares = append(config.Middlewares, middlewares...)
+	}
+}
+
+// tlsConfigOf 返回可修改的TLS配置副本
+func tlsConfigOf(config *ClientConfig) *tls.Config {
+	if config.TLSConfig == nil {
+		return &tls.Config{}
+	}
+	return config.TLSConfig.Clone()
+}
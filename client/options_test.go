@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestOptionsApplyHeadersAndUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "godify-test/1.0" {
+			t.Errorf("Expected User-Agent 'godify-test/1.0', got %s", ua)
+		}
+		if tenant := r.Header.Get("X-Tenant"); tenant != "override" {
+			t.Errorf("Expected request header to override default, got %s", tenant)
+		}
+		if region := r.Header.Get("X-Region"); region != "cn" {
+			t.Errorf("Expected default X-Region 'cn', got %s", region)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewBaseClient(&ClientConfig{BaseURL: server.URL},
+		WithUserAgent("godify-test/1.0"),
+		WithHeaders(map[string]string{"X-Tenant": "default"}),
+		WithHeaders(map[string]string{"X-Region": "cn"}),
+		WithTimeout(5*time.Second),
+		WithMaxRetries(1),
+	)
+	if _, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps", Headers: map[string]string{"X-Tenant": "override"}}); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if c.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %v", c.httpClient.Timeout)
+	}
+	if policy, ok := c.snapshot().RetryPolicy.(*BackoffRetryPolicy); !ok || policy.MaxRetries != 1 {
+		t.Errorf("Expected default retry policy with 1 retry, got %#v", c.snapshot().RetryPolicy)
+	}
+}
+
+func TestOptionsRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// 未信任测试证书时校验失败
+	untrusted := NewBaseClient(&ClientConfig{BaseURL: server.URL, RetryPolicy: &BackoffRetryPolicy{}})
+	if _, err := untrusted.Do(context.Background(), &Request{Method: "GET", Path: "/apps"}); err == nil {
+		t.Error("Expected certificate verification to fail without custom CA")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	trusted := NewBaseClient(&ClientConfig{BaseURL: server.URL, SkipTLS: true}, WithRootCAs(pool))
+	if _, err := trusted.Do(context.Background(), &Request{Method: "GET", Path: "/apps"}); err != nil {
+		t.Errorf("Expected request to succeed with custom CA, got %v", err)
+	}
+	if trusted.snapshot().SkipTLS {
+		t.Error("Expected WithRootCAs to disable SkipTLS")
+	}
+}
+
+func TestOptionsProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	c := NewBaseClient(&ClientConfig{BaseURL: "http://dify.internal/v1"}, WithProxy(http.ProxyURL(proxyURL)))
+	if _, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"}); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if proxied != "http://dify.internal/v1/apps" {
+		t.Errorf("Expected request to go through proxy, got %q", proxied)
+	}
+}
+
+func TestWithMaxRetriesZeroDisablesRetries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewBaseClient(&ClientConfig{BaseURL: server.URL}, WithMaxRetries(0))
+	if _, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"}); err == nil {
+		t.Fatal("Expected 503 to be returned as an error")
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected exactly one attempt, got %d", attempts)
+	}
+}
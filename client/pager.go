@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize 分页遍历默认的每页条数
+const DefaultPageSize = 20
+
+// Page 一页列表数据
+type Page[T any] struct {
+	Items   []T
+	HasMore bool
+	// Cursor 游标分页时下一页的游标（如last_id、first_id），页码分页时为空
+	Cursor string
+}
+
+// PageFetcher 获取一页数据
+//
+// page为从1开始的页码，cursor为上一页返回的游标（首页为空），limit为每页条数；
+// 页码分页的接口忽略cursor，游标分页的接口忽略page。
+type PageFetcher[T any] func(ctx context.Context, page int, cursor string, limit int) (*Page[T], error)
+
+// PagerOptions 分页遍历选项
+type PagerOptions struct {
+	// PageSize 每页条数，默认DefaultPageSize
+	PageSize int
+	// MaxItems 最多返回的条目数，0表示不限制
+	MaxItems int
+	// Prefetch 在消费当前页时后台预取下一页
+	Prefetch bool
+}
+
+// Pager 自动翻页的列表遍历器，同时支持页码分页与游标分页
+type Pager[T any] struct {
+	fetch PageFetcher[T]
+	opts  PagerOptions
+}
+
+// NewPager 创建分页遍历器，opts可为nil
+func NewPager[T any](fetch PageFetcher[T], opts *PagerOptions) *Pager[T] {
+	p := &Pager[T]{fetch: fetch}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.PageSize <= 0 {
+		p.opts.PageSize = DefaultPageSize
+	}
+	return p
+}
+
+// pageResult 预取的一页结果
+type pageResult[T any] struct {
+	page *Page[T]
+	err  error
+}
+
+// Items 以迭代器形式逐条返回全部数据
+//
+// 获取某页失败时返回错误并结束迭代；ctx结束时返回ctx的错误。提前跳出循环会取消正在进行的预取。
+func (p *Pager[T]) Items(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var zero T
+		count := 0
+		pageNum, cursor := 1, ""
+		var prefetched <-chan pageResult[T]
+
+		for {
+			var result pageResult[T]
+			if prefetched != nil {
+				select {
+				case result = <-prefetched:
+				case <-ctx.Done():
+					result.err = ctx.Err()
+				}
+				prefetched = nil
+			} else if err := ctx.Err(); err != nil {
+				result.err = err
+			} else {
+				result.page, result.err = p.fetch(ctx, pageNum, cursor, p.opts.PageSize)
+			}
+			if result.err != nil {
+				yield(zero, result.err)
+				return
+			}
+
+			page := result.page
+			pageNum, cursor = pageNum+1, page.Cursor
+			more := page.HasMore && len(page.Items) > 0
+			if more && p.opts.Prefetch && (p.opts.MaxItems == 0 || count+len(page.Items) < p.opts.MaxItems) {
+				prefetched = p.prefetch(ctx, pageNum, cursor)
+			}
+
+			for _, item := range page.Items {
+				if p.opts.MaxItems > 0 && count >= p.opts.MaxItems {
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+				count++
+			}
+			if !more || (p.opts.MaxItems > 0 && count >= p.opts.MaxItems) {
+				return
+			}
+		}
+	}
+}
+
+// prefetch 在后台获取下一页，通道带缓冲，迭代提前结束时不会阻塞
+func (p *Pager[T]) prefetch(ctx context.Context, pageNum int, cursor string) <-chan pageResult[T] {
+	ch := make(chan pageResult[T], 1)
+	go func() {
+		page, err := p.fetch(ctx, pageNum, cursor, p.opts.PageSize)
+		ch <- pageResult[T]{page: page, err: err}
+	}()
+	return ch
+}
+
+// Collect 将迭代器中的全部数据收集为切片，遇到错误时返回已收集的数据和该错误
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
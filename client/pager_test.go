@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// numberPages 页码分页的测试数据源，共total条
+type numberPages struct {
+	mu    sync.Mutex
+	total int
+	pages []int
+}
+
+func (s *numberPages) fetch(ctx context.Context, page int, _ string, limit int) (*Page[int], error) {
+	s.mu.Lock()
+	s.pages = append(s.pages, page)
+	s.mu.Unlock()
+
+	var items []int
+	for i := (page - 1) * limit; i < page*limit && i < s.total; i++ {
+		items = append(items, i)
+	}
+	return &Page[int]{Items: items, HasMore: page*limit < s.total}, nil
+}
+
+func (s *numberPages) fetched() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.pages...)
+}
+
+func TestPagerPageNumbers(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		t.Run("prefetch="+strconv.FormatBool(prefetch), func(t *testing.T) {
+			source := &numberPages{total: 7}
+			items, err := Collect(NewPager(source.fetch, &PagerOptions{PageSize: 3, Prefetch: prefetch}).Items(context.Background()))
+			if err != nil {
+				t.Fatalf("Collect failed: %v", err)
+			}
+
+			if len(items) != 7 || items[0] != 0 || items[6] != 6 {
+				t.Errorf("Expected items 0..6, got %v", items)
+			}
+			if pages := source.fetched(); len(pages) != 3 || pages[2] != 3 {
+				t.Errorf("Expected pages [1 2 3], got %v", pages)
+			}
+		})
+	}
+}
+
+func TestPagerCursor(t *testing.T) {
+	data := []string{"a", "b", "c", "d", "e"}
+	var cursors []string
+	fetch := func(ctx context.Context, _ int, cursor string, limit int) (*Page[string], error) {
+		cursors = append(cursors, cursor)
+		start := 0
+		for i, id := range data {
+			if id == cursor {
+				start = i + 1
+			}
+		}
+		end := min(start+limit, len(data))
+		return &Page[string]{Items: data[start:end], HasMore: end < len(data), Cursor: data[end-1]}, nil
+	}
+
+	items, err := Collect(NewPager(fetch, &PagerOptions{PageSize: 2}).Items(context.Background()))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if len(items) != 5 || items[4] != "e" {
+		t.Errorf("Expected all 5 items, got %v", items)
+	}
+	if len(cursors) != 3 || cursors[0] != "" || cursors[1] != "b" || cursors[2] != "d" {
+		t.Errorf("Expected cursors ['' b d], got %q", cursors)
+	}
+}
+
+func TestPagerMaxItemsAndBreak(t *testing.T) {
+	source := &numberPages{total: 100}
+	items, err := Collect(NewPager(source.fetch, &PagerOptions{PageSize: 10, MaxItems: 15, Prefetch: true}).Items(context.Background()))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(items) != 15 {
+		t.Errorf("Expected 15 items, got %d", len(items))
+	}
+	if pages := source.fetched(); len(pages) != 2 {
+		t.Errorf("Expected no page fetched beyond MaxItems, got %v", pages)
+	}
+
+	source = &numberPages{total: 100}
+	for item := range NewPager(source.fetch, nil).Items(context.Background()) {
+		if item == 5 {
+			break
+		}
+	}
+	if pages := source.fetched(); len(pages) != 1 {
+		t.Errorf("Expected a single page fetched before break, got %v", pages)
+	}
+}
+
+func TestPagerErrors(t *testing.T) {
+	failure := errors.New("server error")
+	fetch := func(ctx context.Context, page int, _ string, limit int) (*Page[int], error) {
+		if page == 2 {
+			return nil, failure
+		}
+		return &Page[int]{Items: []int{1, 2}, HasMore: true}, nil
+	}
+
+	items, err := Collect(NewPager(fetch, &PagerOptions{Prefetch: true}).Items(context.Background()))
+	if !errors.Is(err, failure) || len(items) != 2 {
+		t.Errorf("Expected 2 items and fetch error, got %v, %v", items, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var seen int
+	for _, err = range NewPager(fetch, nil).Items(ctx) {
+		if err != nil {
+			break
+		}
+		seen++
+		cancel()
+	}
+	if !errors.Is(err, context.Canceled) || seen != 2 {
+		t.Errorf("Expected context.Canceled after first page, got %d items, %v", seen, err)
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"net"
+
+	"github.com/kingfs/godify/errors"
+)
+
+// RequestIDHeader 请求ID头，未设置时由客户端生成
+const RequestIDHeader = "X-Request-Id"
+
+// withRequestID 确保请求带有请求ID，需要生成时复制请求以免修改调用方的Headers
+func withRequestID(req *Request) *Request {
+	if req.Headers[RequestIDHeader] != "" {
+		return req
+	}
+
+	clone := *req
+	clone.Headers = make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		clone.Headers[k] = v
+	}
+	clone.Headers[RequestIDHeader] = newRequestID()
+	return &clone
+}
+
+// newRequestID 生成随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestInfo 构建错误中携带的请求信息
+func requestInfo(req *Request, attempts int) errors.RequestInfo {
+	return errors.RequestInfo{
+		Method:    req.Method,
+		Path:      req.Path,
+		Attempts:  attempts,
+		RequestID: req.Headers[RequestIDHeader],
+	}
+}
+
+// transportError 将未得到响应的错误转换为类型化错误
+//
+// 调用方主动取消不属于传输故障，保留原始错误链。
+func transportError(info errors.RequestInfo, err error) error {
+	if stderrors.Is(err, context.Canceled) {
+		return fmt.Errorf("request canceled: %w", err)
+	}
+
+	var netErr net.Error
+	if stderrors.Is(err, context.DeadlineExceeded) || (stderrors.As(err, &netErr) && netErr.Timeout()) {
+		return &errors.TimeoutError{RequestInfo: info, Err: err}
+	}
+	return &errors.NetworkError{RequestInfo: info, Err: err}
+}
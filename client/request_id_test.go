@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kingfs/godify/errors"
+)
+
+func TestDoReturnsNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	c := NewBaseClient(&ClientConfig{
+		BaseURL:     baseURL,
+		RetryPolicy: &BackoffRetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	})
+
+	_, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"})
+
+	var netErr *errors.NetworkError
+	if !stderrors.As(err, &netErr) {
+		t.Fatalf("Expected *errors.NetworkError, got %T: %v", err, err)
+	}
+	if netErr.Method != "GET" || netErr.Path != "/apps" || netErr.Attempts != 3 || netErr.RequestID == "" {
+		t.Errorf("Unexpected request info: %+v", netErr.RequestInfo)
+	}
+}
+
+func TestDoReturnsTimeoutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := NewBaseClient(&ClientConfig{
+		BaseURL:     server.URL,
+		Timeout:     50 * time.Millisecond,
+		RetryPolicy: &BackoffRetryPolicy{},
+	})
+
+	_, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps"})
+
+	var timeoutErr *errors.TimeoutError
+	if !stderrors.As(err, &timeoutErr) {
+		t.Fatalf("Expected *errors.TimeoutError, got %T: %v", err, err)
+	}
+	if timeoutErr.Attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", timeoutErr.Attempts)
+	}
+}
+
+func TestDoJSONReturnsDecodeError(t *testing.T) {
+	var serverRequestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverRequestID = r.Header.Get(RequestIDHeader)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>502 Bad Gateway</html>"))
+	}))
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL)
+	var result map[string]interface{}
+	err := c.DoJSON(context.Background(), &Request{Method: "GET", Path: "/parameters"}, &result)
+
+	var decodeErr *errors.DecodeError
+	if !stderrors.As(err, &decodeErr) {
+		t.Fatalf("Expected *errors.DecodeError, got %T: %v", err, err)
+	}
+	if decodeErr.Body != "<html>502 Bad Gateway</html>" || decodeErr.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected decode error: %+v", decodeErr)
+	}
+	if serverRequestID == "" || decodeErr.RequestID != serverRequestID {
+		t.Errorf("Expected request ID %q sent to server, got %q", serverRequestID, decodeErr.RequestID)
+	}
+}
+
+func TestDoKeepsCallerRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": "invalid_param", "message": "bad"}`))
+	}))
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL)
+	_, err := c.Do(context.Background(), &Request{Method: "GET", Path: "/apps", Headers: map[string]string{RequestIDHeader: "trace-1"}})
+
+	apiErr := errors.GetAPIError(err)
+	if apiErr == nil || apiErr.RequestID != "trace-1" {
+		t.Errorf("Expected APIError with request ID 'trace-1', got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 请求重试策略
+type RetryPolicy interface {
+	// ShouldRetry 判断第attempt次（从0开始）请求失败后是否重试，并返回重试前的等待时间。
+	// resp与err二者之一非空，elapsed为首次请求开始至今的耗时。
+	ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int, elapsed time.Duration) (time.Duration, bool)
+}
+
+// BackoffRetryPolicy 指数退避重试策略
+//
+// 幂等方法（GET、HEAD、OPTIONS、PUT、DELETE）在网络错误和可重试状态码时重试；
+// 非幂等方法（如POST）默认只在429时重试，因为此时服务端尚未处理请求，
+// 携带Idempotency-Key请求头或设置RetryNonIdempotent后按幂等方法处理。
+// 429与503响应携带Retry-After时使用服务端指定的等待时间，
+// 超过MaxDelay或剩余的MaxElapsed预算时不再重试，避免在服务端允许之前重试。
+type BackoffRetryPolicy struct {
+	// MaxRetries 最大重试次数
+	MaxRetries int
+	// BaseDelay 首次重试的等待时间，之后每次翻倍
+	BaseDelay time.Duration
+	// MaxDelay 单次等待的上限
+	MaxDelay time.Duration
+	// MaxElapsed 重试的总耗时预算，0表示不限制
+	MaxElapsed time.Duration
+	// Jitter 抖动比例（0-1），实际等待时间在[delay*(1-Jitter), delay]之间随机
+	Jitter float64
+	// RetryNonIdempotent 是否对非幂等请求在网络错误和5xx时重试
+	RetryNonIdempotent bool
+	// RetryableStatus 可重试的状态码，为nil时使用默认集合
+	RetryableStatus map[int]bool
+}
+
+// defaultRetryableStatus 默认可重试的状态码
+var defaultRetryableStatus = map[int]bool{
+	http.StatusRequestTimeout:      true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// NewBackoffRetryPolicy 创建默认参数的指数退避重试策略
+func NewBackoffRetryPolicy(maxRetries int) *BackoffRetryPolicy {
+	return &BackoffRetryPolicy{
+		MaxRetries: maxRetries,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+		MaxElapsed: 2 * time.Minute,
+		Jitter:     0.2,
+	}
+}
+
+// ShouldRetry 判断是否重试
+func (p *BackoffRetryPolicy) ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int, elapsed time.Duration) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+
+	idempotent := p.RetryNonIdempotent || isIdempotent(req)
+
+	var wait time.Duration
+	switch {
+	case err != nil:
+		// 请求上下文已结束时不再重试
+		if req.Context().Err() != nil || !idempotent {
+			return 0, false
+		}
+	case resp != nil:
+		status := p.RetryableStatus
+		if status == nil {
+			status = defaultRetryableStatus
+		}
+		if !status[resp.StatusCode] {
+			return 0, false
+		}
+		if resp.StatusCode != http.StatusTooManyRequests && !idempotent {
+			return 0, false
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			wait, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+	default:
+		return 0, false
+	}
+
+	if wait == 0 {
+		wait = p.backoff(attempt)
+	} else if p.MaxDelay > 0 && wait > p.MaxDelay {
+		return 0, false
+	}
+
+	if p.MaxElapsed > 0 && elapsed+wait > p.MaxElapsed {
+		return 0, false
+	}
+	return wait, true
+}
+
+// backoff 计算第attempt次重试的退避时间
+func (p *BackoffRetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// isIdempotent 判断请求是否可安全重放
+func isIdempotent(req *http.Request) bool {
+	return isIdempotentMethod(req.Method) || req.Header.Get("Idempotency-Key") != ""
+}
+
+// isIdempotentMethod 判断HTTP方法是否幂等
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter 解析Retry-After头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
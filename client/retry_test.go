@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestRequest(method string) *http.Request {
+	req, _ := http.NewRequest(method, "http://example.com/v1/chat-messages", nil)
+	return req
+}
+
+func TestBackoffRetryPolicyIdempotency(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3)
+	serverError := &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}
+	rateLimited := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		resp   *http.Response
+		err    error
+		expect bool
+	}{
+		{"GET 500", newRetryTestRequest("GET"), serverError, nil, true},
+		{"POST 500", newRetryTestRequest("POST"), serverError, nil, false},
+		{"POST 429", newRetryTestRequest("POST"), rateLimited, nil, true},
+		{"POST network error", newRetryTestRequest("POST"), nil, errors.New("connection reset"), false},
+		{"DELETE network error", newRetryTestRequest("DELETE"), nil, errors.New("connection reset"), true},
+		{"GET 400", newRetryTestRequest("GET"), &http.Response{StatusCode: http.StatusBadRequest}, nil, false},
+	}
+
+	keyed := newRetryTestRequest("POST")
+	keyed.Header.Set("Idempotency-Key", "abc")
+	tests = append(tests, struct {
+		name   string
+		req    *http.Request
+		resp   *http.Response
+		err    error
+		expect bool
+	}{"POST 500 with Idempotency-Key", keyed, serverError, nil, true})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, retry := policy.ShouldRetry(tt.req, tt.resp, tt.err, 0, 0)
+			if retry != tt.expect {
+				t.Errorf("Expected retry=%v, got %v", tt.expect, retry)
+			}
+		})
+	}
+}
+
+func TestBackoffRetryPolicyDelays(t *testing.T) {
+	policy := &BackoffRetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+		MaxElapsed: 10 * time.Second,
+	}
+	req := newRetryTestRequest("GET")
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+
+	for attempt, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		wait, retry := policy.ShouldRetry(req, resp, nil, attempt, 0)
+		if !retry || wait != expected {
+			t.Errorf("Attempt %d: expected wait %v, got %v (retry=%v)", attempt, expected, wait, retry)
+		}
+	}
+
+	if _, retry := policy.ShouldRetry(req, resp, nil, 5, 0); retry {
+		t.Error("Expected no retry after MaxRetries")
+	}
+
+	if _, retry := policy.ShouldRetry(req, resp, nil, 1, 9900*time.Millisecond); retry {
+		t.Error("Expected no retry beyond MaxElapsed")
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		wait, _ := policy.ShouldRetry(req, resp, nil, 2, 0)
+		if wait < 200*time.Millisecond || wait > 400*time.Millisecond {
+			t.Fatalf("Expected jittered wait within [200ms, 400ms], got %v", wait)
+		}
+	}
+}
+
+func TestBackoffRetryPolicyRetryAfter(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3)
+	req := newRetryTestRequest("POST")
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}
+	wait, retry := policy.ShouldRetry(req, resp, nil, 0, 0)
+	if !retry || wait != 7*time.Second {
+		t.Errorf("Expected 7s wait from Retry-After, got %v (retry=%v)", wait, retry)
+	}
+
+	date := time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat)
+	resp.Header.Set("Retry-After", date)
+	wait, retry = policy.ShouldRetry(req, resp, nil, 0, 0)
+	if !retry || wait <= time.Second || wait > 3*time.Second {
+		t.Errorf("Expected about 3s wait from Retry-After date, got %v (retry=%v)", wait, retry)
+	}
+}
+
+func TestBackoffRetryPolicyRetryAfterBeyondLimits(t *testing.T) {
+	policy := &BackoffRetryPolicy{MaxRetries: 3, MaxDelay: 10 * time.Second, MaxElapsed: time.Minute}
+	req := newRetryTestRequest("GET")
+
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"30"}}}
+	if wait, retry := policy.ShouldRetry(req, resp, nil, 0, 0); retry {
+		t.Errorf("Expected no retry when Retry-After exceeds MaxDelay, got wait %v", wait)
+	}
+
+	resp.Header.Set("Retry-After", "8")
+	if wait, retry := policy.ShouldRetry(req, resp, nil, 0, 55*time.Second); retry {
+		t.Errorf("Expected no retry when Retry-After exceeds the remaining budget, got wait %v", wait)
+	}
+	if wait, retry := policy.ShouldRetry(req, resp, nil, 0, 0); !retry || wait != 8*time.Second {
+		t.Errorf("Expected 8s wait within limits, got %v (retry=%v)", wait, retry)
+	}
+}
+
+func TestDoRetriesRateLimitedRequest(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code": "too_many_requests", "message": "slow down"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"answer": "ok"}`))
+	}))
+	defer server.Close()
+
+	c := NewBaseClient(&ClientConfig{
+		BaseURL:     server.URL,
+		RetryPolicy: &BackoffRetryPolicy{MaxRetries: 2, BaseDelay: 10 * time.Millisecond},
+	})
+
+	var result map[string]string
+	err := c.DoJSON(context.Background(), &Request{Method: "GET", Path: "/messages"}, &result)
+	if err != nil {
+		t.Fatalf("DoJSON failed: %v", err)
+	}
+
+	if attempts != 2 || result["answer"] != "ok" {
+		t.Errorf("Expected success on second attempt, got %d attempts and %v", attempts, result)
+	}
+}
+
+func TestDoRetryWaitHonoursContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "20")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Do(ctx, &Request{Method: "GET", Path: "/apps"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Error("Expected retry wait to be interrupted by context")
+	}
+}
+
+// payloadRecorder 记录每次请求的请求体，前failures次返回429
+type payloadRecorder struct {
+	mu       sync.Mutex
+	payloads []string
+	failures int
+}
+
+func (p *payloadRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	p.mu.Lock()
+	p.payloads = append(p.payloads, r.Header.Get("Content-Type")+"\n"+string(body))
+	attempt := len(p.payloads)
+	p.mu.Unlock()
+
+	if attempt <= p.failures {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"code": "too_many_requests", "message": "slow down"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"id": "ok"}`))
+}
+
+func newRetryingTestClient(baseURL string) *BaseClient {
+	return NewBaseClient(&ClientConfig{
+		BaseURL:     baseURL,
+		RetryPolicy: &BackoffRetryPolicy{MaxRetries: 3, BaseDelay: 5 * time.Millisecond},
+	})
+}
+
+func TestDoRetriedChatCarriesIdenticalPayload(t *testing.T) {
+	recorder := &payloadRecorder{failures: 2}
+	server := httptest.NewUnstartedServer(recorder)
+	var conns int32
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	c := newRetryingTestClient(server.URL)
+	body := map[string]interface{}{"query": "Hello", "user": "test-user", "response_mode": "blocking"}
+	var result map[string]string
+	if err := c.DoJSON(context.Background(), &Request{Method: "POST", Path: "/chat-messages", Body: body}, &result); err != nil {
+		t.Fatalf("DoJSON failed: %v", err)
+	}
+
+	if len(recorder.payloads) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(recorder.payloads))
+	}
+	for i, payload := range recorder.payloads {
+		if payload != recorder.payloads[0] || !strings.Contains(payload, `"query":"Hello"`) {
+			t.Errorf("Attempt %d sent a different payload: %q", i+1, payload)
+		}
+	}
+
+	// 中间响应被读取并关闭后连接可以复用
+	if conns != 1 {
+		t.Errorf("Expected retries to reuse one connection, got %d connections", conns)
+	}
+}
+
+func TestDoRetriedUploadCarriesIdenticalPayload(t *testing.T) {
+	recorder := &payloadRecorder{failures: 1}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	c := newRetryingTestClient(server.URL)
+	fileData := []byte(strings.Repeat("document content\n", 512))
+	if _, err := c.UploadFile(context.Background(), "/files/upload", "file", "doc.txt", fileData, map[string]string{"user": "test-user"}); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if len(recorder.payloads) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(recorder.payloads))
+	}
+	if recorder.payloads[0] != recorder.payloads[1] {
+		t.Error("Expected retried upload to carry the identical multipart payload")
+	}
+	if !strings.Contains(recorder.payloads[1], string(fileData)) {
+		t.Error("Expected retried upload to contain the full file data")
+	}
+}
+
+func TestDoDoesNotRetryStreamingUpload(t *testing.T) {
+	recorder := &payloadRecorder{failures: 1}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	c := newRetryingTestClient(server.URL)
+	_, err := c.UploadFileReader(context.Background(), "/files/upload", "file", "doc.txt", strings.NewReader("data"), nil)
+	if err == nil {
+		t.Fatal("Expected 429 error for non-replayable upload")
+	}
+
+	if len(recorder.payloads) != 1 {
+		t.Errorf("Expected a single attempt, got %d", len(recorder.payloads))
+	}
+}
@@ -1,12 +1,15 @@
 package client
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
+
+	"github.com/kingfs/godify/errors"
 )
 
 // SSEEvent SSE事件
@@ -17,6 +20,10 @@ type SSEEvent struct {
 	Retry string
 }
 
+// ErrStopStream 由SSEHandler.OnEvent返回时提前结束流式读取，
+// StreamResponse将关闭连接并返回nil
+var ErrStopStream = stderrors.New("stop stream")
+
 // SSEHandler SSE事件处理器
 type SSEHandler interface {
 	OnEvent(event *SSEEvent) error
@@ -24,88 +31,312 @@ type SSEHandler interface {
 	OnComplete()
 }
 
+// StreamReconnectConfig SSE断线重连配置
+//
+// 重连会重新发送原始请求，只对幂等请求或设置了Request.Resumable的请求生效；
+// Dify的对话和工作流接口不支持续传，重发POST会重新生成并重复计费。
+type StreamReconnectConfig struct {
+	// MaxAttempts 连续重连的最大次数，重连后收到新事件会重新计数
+	MaxAttempts int
+	// RetryInterval 默认重连间隔，服务端下发的retry字段优先
+	RetryInterval time.Duration
+	// OnReconnect 每次重连前回调
+	OnReconnect func(attempt int, lastEventID string, err error)
+}
+
+// defaultStreamRetryInterval 未配置且服务端未下发retry时的重连间隔
+const defaultStreamRetryInterval = time.Second
+
+// maxSeenEventIDs 用于去重的事件ID上限，超出后淘汰最早的ID
+const maxSeenEventIDs = 1024
+
+// streamTerminalEvents 表示流正常结束的事件，未收到时连接关闭视为中断
+var streamTerminalEvents = map[string]bool{
+	"message_end":       true,
+	"workflow_finished": true,
+	"error":             true,
+}
+
+// streamState 跨连接共享的流状态
+type streamState struct {
+	started     bool
+	lastEventID string
+	retry       time.Duration
+	seen        map[string]struct{}
+	seenOrder   []string
+	terminated  bool
+	eventCount  int
+	lastEvent   *SSEEvent
+	request     errors.RequestInfo
+}
+
 // StreamResponse 流式响应处理
+//
+// 响应体按行增量读取，每个事件在到达时立即分发给handler；
+// ctx取消时连接会被关闭并返回ctx.Err()。
+// 未收到结束事件（message_end、workflow_finished、error）时连接关闭返回 *errors.StreamError。
+// 配置了StreamReconnect且请求可重放（幂等方法或Request.Resumable）时，
+// 连接中断后会携带Last-Event-ID重新连接，并跳过已处理过的事件ID。
 func (c *BaseClient) StreamResponse(ctx context.Context, req *Request, handler SSEHandler) error {
-	startTime := time.Now()
-
 	// 记录流式请求开始
-	c.logger.InfoContext(ctx, "Starting streaming request", "method", req.Method, "path", req.Path)
+	c.log().InfoContext(ctx, "Starting streaming request", "method", req.Method, "path", req.Path)
+
+	req = withRequestID(req)
+	state := &streamState{seen: make(map[string]struct{})}
+	err := c.streamWithReconnect(ctx, req, handler, state)
+
+	// 只有开始解析后才通知handler，与建立连接前的错误区分
+	if state.started {
+		if err != nil {
+			handler.OnError(err)
+		}
+		c.log().InfoContext(ctx, "SSE stream parsing completed", "event_count", state.eventCount)
+		handler.OnComplete()
+	}
+
+	return err
+}
+
+// streamWithReconnect 执行流式请求，按配置在连接中断时重连
+func (c *BaseClient) streamWithReconnect(ctx context.Context, req *Request, handler SSEHandler, state *streamState) error {
+	reconnect := c.snapshot().StreamReconnect
+	if !resumable(req) {
+		reconnect = nil
+	}
+	attempt := 0
+
+	for {
+		eventCount := state.eventCount
+		retryable, err := c.streamOnce(ctx, req, handler, state)
+		if err == nil || !retryable || reconnect == nil || ctx.Err() != nil {
+			return err
+		}
+
+		// 上次连接有进展则重新计数
+		if state.eventCount > eventCount {
+			attempt = 0
+		}
+		if attempt >= reconnect.MaxAttempts {
+			return err
+		}
+		attempt++
+
+		wait := state.retry
+		if wait == 0 {
+			wait = reconnect.RetryInterval
+		}
+		if wait == 0 {
+			wait = defaultStreamRetryInterval
+		}
+
+		c.log().WarnContext(ctx, "SSE stream interrupted, reconnecting", "attempt", attempt, "last_event_id", state.lastEventID, "wait", wait, "error", err)
+		if reconnect.OnReconnect != nil {
+			reconnect.OnReconnect(attempt, state.lastEventID, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		req = withLastEventID(req, state.lastEventID)
+	}
+}
+
+// resumable 判断流式请求中断后能否重新发送
+func resumable(req *Request) bool {
+	return req.Resumable || isIdempotentMethod(req.Method) || req.Headers["Idempotency-Key"] != ""
+}
+
+// withLastEventID 复制请求并设置Last-Event-ID头
+func withLastEventID(req *Request, lastEventID string) *Request {
+	if lastEventID == "" {
+		return req
+	}
+
+	reconnectReq := *req
+	reconnectReq.Headers = make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		reconnectReq.Headers[k] = v
+	}
+	reconnectReq.Headers["Last-Event-ID"] = lastEventID
+	return &reconnectReq
+}
+
+// streamOnce 建立一次流式连接并解析，retryable表示错误是否可通过重连恢复
+func (c *BaseClient) streamOnce(ctx context.Context, req *Request, handler SSEHandler, state *streamState) (bool, error) {
+	startTime := time.Now()
 
 	// 执行请求
-	resp, err := c.Do(ctx, req)
+	resp, err := c.chain(c.streamRoundTrip)(ctx, req)
 	if err != nil {
-		c.logger.ErrorContext(ctx, "Streaming request failed", "error", err)
-		return err
+		c.log().ErrorContext(ctx, "Streaming request failed", "error", err)
+		// 首次连接失败已经过请求重试，仅在重连时继续尝试
+		return state.started, err
 	}
 
+	state.request = errors.RequestInfo{Method: req.Method, Path: req.Path, Attempts: resp.Attempts, RequestID: resp.RequestID}
+
+	// 错误响应不是SSE流，响应体已完整读取
+	if resp.Stream == nil {
+		c.metrics.RecordRequest(false, time.Since(startTime))
+		c.log().ErrorContext(ctx, "Streaming request failed", "status_code", resp.StatusCode, "body", string(resp.Body))
+		return false, c.parseError(ctx, resp)
+	}
+	defer func() {
+		if closeErr := resp.Stream.Close(); closeErr != nil {
+			c.log().DebugContext(ctx, "Failed to close stream body", "error", closeErr)
+		}
+	}()
+
 	// 检查Content-Type是否为SSE
 	contentType := resp.Headers.Get("Content-Type")
 	if !strings.Contains(contentType, "text/event-stream") && !strings.Contains(contentType, "text/plain") {
 		err := fmt.Errorf("unexpected content type for streaming response: %s", contentType)
-		c.logger.ErrorContext(ctx, "Invalid content type for streaming", "error", err)
-		return err
+		c.log().ErrorContext(ctx, "Invalid content type for streaming", "error", err)
+		return false, err
 	}
 
-	// 记录监控指标
+	// 记录监控指标（首字节时间）
 	duration := time.Since(startTime)
 	c.metrics.RecordRequest(true, duration)
 
 	// 解析SSE流
-	return c.parseSSEStream(ctx, resp.Body, handler)
+	state.started = true
+	return c.parseSSEStream(ctx, resp.Stream, handler, state)
 }
 
-// parseSSEStream 解析SSE数据流
-func (c *BaseClient) parseSSEStream(ctx context.Context, data []byte, handler SSEHandler) error {
-	defer func() {
-		c.logger.InfoContext(ctx, "SSE stream parsing completed")
-		handler.OnComplete()
-	}()
+// streamRoundTrip 发送流式请求，成功时返回未读的响应流，是流式中间件链的最内层
+func (c *BaseClient) streamRoundTrip(ctx context.Context, req *Request) (*Response, error) {
+	resp, attempts, err := c.send(ctx, c.streamClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *Response
+	if resp.StatusCode < 400 {
+		response = newResponse(resp, nil)
+		response.Stream = resp.Body
+	} else {
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, transportError(requestInfo(req, attempts), err)
+		}
+		response = newResponse(resp, respBody)
+	}
 
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	var event SSEEvent
-	eventCount := 0
+	response.RequestID = req.Headers[RequestIDHeader]
+	response.Attempts = attempts
+	response.Method, response.Path = req.Method, req.Path
+	return response, nil
+}
 
-	c.logger.DebugContext(ctx, "Starting SSE stream parsing")
+// parseSSEStream 解析SSE数据流，retryable表示读取中断可通过重连恢复
+func (c *BaseClient) parseSSEStream(ctx context.Context, r io.Reader, handler SSEHandler, state *streamState) (bool, error) {
+	parser := newSSEParser(r, c.snapshot().SSEMaxLineSize)
+	parser.lastEventID = state.lastEventID
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	c.log().DebugContext(ctx, "Starting SSE stream parsing")
 
-		// 空行表示事件结束
-		if line == "" {
-			if event.Data != "" || event.Event != "" {
-				eventCount++
-				c.logger.DebugContext(ctx, "Processing SSE event", "event_type", event.Event, "event_id", event.ID, "data_size", len(event.Data))
+	for {
+		event, err := parser.Next()
+		if parser.retry > 0 {
+			state.retry = parser.retry
+		}
+		state.lastEventID = parser.lastEventID
 
-				if err := handler.OnEvent(&event); err != nil {
-					c.logger.ErrorContext(ctx, "Failed to process SSE event", "error", err)
-					handler.OnError(err)
-					return err
-				}
+		if err == io.EOF {
+			if !state.terminated {
+				c.log().ErrorContext(ctx, "SSE stream closed before terminal event", "event_count", state.eventCount)
+				return true, state.streamError(io.ErrUnexpectedEOF)
+			}
+			break
+		}
+		if err != nil {
+			// ctx取消会导致连接关闭，此时优先返回ctx的错误
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return false, ctxErr
 			}
-			event = SSEEvent{}
-			continue
+			c.log().ErrorContext(ctx, "SSE stream scanning error", "error", err)
+			return !stderrors.Is(err, ErrSSELineTooLong), state.streamError(err)
 		}
 
-		// 解析SSE字段
-		if strings.HasPrefix(line, "data: ") {
-			event.Data = strings.TrimPrefix(line, "data: ")
-		} else if strings.HasPrefix(line, "event: ") {
-			event.Event = strings.TrimPrefix(line, "event: ")
-		} else if strings.HasPrefix(line, "id: ") {
-			event.ID = strings.TrimPrefix(line, "id: ")
-		} else if strings.HasPrefix(line, "retry: ") {
-			event.Retry = strings.TrimPrefix(line, "retry: ")
+		if err := ctx.Err(); err != nil {
+			return false, err
 		}
+
+		if err := c.dispatchSSEEvent(ctx, event, handler, state); err != nil {
+			if stderrors.Is(err, ErrStopStream) {
+				c.log().DebugContext(ctx, "SSE stream stopped by handler", "event_count", state.eventCount)
+				return false, nil
+			}
+			c.log().ErrorContext(ctx, "Failed to process SSE event", "error", err)
+			return false, err
+		}
+	}
+
+	c.log().InfoContext(ctx, "SSE stream parsing completed successfully", "event_count", state.eventCount)
+	return false, nil
+}
+
+// dispatchSSEEvent 记录事件ID，跳过重复事件后分发给handler
+func (c *BaseClient) dispatchSSEEvent(ctx context.Context, event *SSEEvent, handler SSEHandler, state *streamState) error {
+	if event.ID != "" {
+		if _, ok := state.seen[event.ID]; ok {
+			c.log().DebugContext(ctx, "Skipping duplicate SSE event", "event_id", event.ID)
+			return nil
+		}
+		state.markSeen(event.ID)
 	}
 
-	if err := scanner.Err(); err != nil {
-		c.logger.ErrorContext(ctx, "SSE stream scanning error", "error", err)
-		handler.OnError(err)
-		return err
+	state.eventCount++
+	state.lastEvent = event
+	if streamTerminalEvents[sseEventName(event)] {
+		state.terminated = true
 	}
+	c.log().DebugContext(ctx, "Processing SSE event", "event_type", event.Event, "event_id", event.ID, "data_size", len(event.Data))
 
-	c.logger.InfoContext(ctx, "SSE stream parsing completed successfully", "event_count", eventCount)
-	return nil
+	return handler.OnEvent(event)
+}
+
+// markSeen 记录已处理的事件ID，超出maxSeenEventIDs时淘汰最早的ID
+func (s *streamState) markSeen(id string) {
+	if len(s.seenOrder) >= maxSeenEventIDs {
+		delete(s.seen, s.seenOrder[0])
+		s.seenOrder = s.seenOrder[1:]
+	}
+	s.seen[id] = struct{}{}
+	s.seenOrder = append(s.seenOrder, id)
+}
+
+// streamError 以当前流状态包装读取错误
+func (s *streamState) streamError(err error) *errors.StreamError {
+	return &errors.StreamError{
+		RequestInfo: s.request,
+		LastEvent:   sseEventName(s.lastEvent),
+		LastEventID: s.lastEventID,
+		EventCount:  s.eventCount,
+		Err:         err,
+	}
+}
+
+// sseEventName 返回事件类型，Dify将类型放在data的event字段中
+func sseEventName(event *SSEEvent) string {
+	if event == nil {
+		return ""
+	}
+	if event.Event != "" {
+		return event.Event
+	}
+	var base struct {
+		Event string `json:"event"`
+	}
+	_ = json.Unmarshal([]byte(event.Data), &base)
+	return base.Event
 }
 
 // JSONSSEHandler 将SSE事件解析为JSON的处理器
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSSEMaxLineSize SSE单行的默认最大长度
+const DefaultSSEMaxLineSize = 8 << 20
+
+// ErrSSELineTooLong SSE单行超过最大长度
+var ErrSSELineTooLong = stderrors.New("sse line too long")
+
+// sseParser 按WHATWG EventSource规范解析SSE流
+//
+// 与规范的差异：未指定event字段时Event保持为空（而非"message"），
+// 因为Dify将事件类型放在data的event字段中；SSEEvent.ID仅为当前事件的id字段，
+// 规范意义上持续生效的last event ID由lastEventID记录。
+type sseParser struct {
+	scanner     *bufio.Scanner
+	maxLineSize int
+	started     bool
+
+	// lastEventID 规范中的last event ID缓冲区，跨事件保留
+	lastEventID string
+	// retry 服务端通过retry字段下发的重连间隔，未下发时为0
+	retry time.Duration
+}
+
+// newSSEParser 创建SSE解析器，maxLineSize<=0时使用DefaultSSEMaxLineSize
+func newSSEParser(r io.Reader, maxLineSize int) *sseParser {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultSSEMaxLineSize
+	}
+
+	initialSize := 64 * 1024
+	if initialSize > maxLineSize {
+		initialSize = maxLineSize
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, initialSize), maxLineSize)
+	scanner.Split(scanSSELines)
+
+	return &sseParser{
+		scanner:     scanner,
+		maxLineSize: maxLineSize,
+	}
+}
+
+// Next 返回下一个完整事件，流正常结束时返回io.EOF
+//
+// 流结束时尚未以空行结尾的事件按规范丢弃。
+func (p *sseParser) Next() (*SSEEvent, error) {
+	var event SSEEvent
+	var data strings.Builder
+	hasData := false
+
+	for p.scanner.Scan() {
+		line := p.scanner.Text()
+		if !p.started {
+			p.started = true
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+
+		// 空行表示事件结束，data为空时不分发
+		if line == "" {
+			if !hasData {
+				event = SSEEvent{}
+				continue
+			}
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			return &event, nil
+		}
+
+		// 冒号开头为注释，常用作保活
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				event.ID = value
+				p.lastEventID = value
+			}
+		case "retry":
+			if isASCIIDigits(value) {
+				if ms, err := strconv.Atoi(value); err == nil {
+					event.Retry = value
+					p.retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}
+
+	if err := p.scanner.Err(); err != nil {
+		if stderrors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: limit is %d bytes", ErrSSELineTooLong, p.maxLineSize)
+		}
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// scanSSELines 按CRLF、LF或单独的CR切分行
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// 末尾为CR，需要更多数据判断是否为CRLF
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// isASCIIDigits 判断字符串是否仅由ASCII数字组成
+func isASCIIDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
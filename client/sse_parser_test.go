@@ -0,0 +1,169 @@
+package client
+
+import (
+	stderrors "errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseAll 解析输入中的全部事件
+func parseAll(t *testing.T, input string, maxLineSize int) ([]SSEEvent, *sseParser, error) {
+	t.Helper()
+
+	parser := newSSEParser(strings.NewReader(input), maxLineSize)
+	var events []SSEEvent
+	for {
+		event, err := parser.Next()
+		if err == io.EOF {
+			return events, parser, nil
+		}
+		if err != nil {
+			return events, parser, err
+		}
+		events = append(events, *event)
+	}
+}
+
+func TestSSEParserConformance(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []SSEEvent
+	}{
+		{
+			name:  "field with space",
+			input: "data: hello\n\n",
+			want:  []SSEEvent{{Data: "hello"}},
+		},
+		{
+			name:  "field without space",
+			input: "event:message\ndata:hello\n\n",
+			want:  []SSEEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:  "only one leading space is removed",
+			input: "data:  hello\n\n",
+			want:  []SSEEvent{{Data: " hello"}},
+		},
+		{
+			name:  "multi-line data is joined with LF",
+			input: "data: first\ndata: second\ndata:third\n\n",
+			want:  []SSEEvent{{Data: "first\nsecond\nthird"}},
+		},
+		{
+			name:  "comments are ignored",
+			input: ": keepalive\ndata: a\n:another comment\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "leading BOM is stripped",
+			input: "\uFEFFdata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "data: a\r\nid: 1\r\n\r\ndata: b\r\n\r\n",
+			want:  []SSEEvent{{Data: "a", ID: "1"}, {Data: "b"}},
+		},
+		{
+			name:  "CR line endings",
+			input: "data: a\rdata: b\r\rdata: c\r\r",
+			want:  []SSEEvent{{Data: "a\nb"}, {Data: "c"}},
+		},
+		{
+			name:  "event without data is not dispatched",
+			input: "event: ping\n\ndata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "field name without colon",
+			input: "data\n\n",
+			want:  []SSEEvent{{Data: ""}},
+		},
+		{
+			name:  "two empty data lines",
+			input: "data\ndata\n\n",
+			want:  []SSEEvent{{Data: "\n"}},
+		},
+		{
+			name:  "unknown fields are ignored",
+			input: "foo: bar\ndata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "id containing NULL is ignored",
+			input: "id: a\x00b\ndata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "non-digit retry is ignored",
+			input: "retry: 10s\ndata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "valid retry",
+			input: "retry: 1500\ndata: a\n\n",
+			want:  []SSEEvent{{Data: "a", Retry: "1500"}},
+		},
+		{
+			name:  "incomplete trailing event is discarded",
+			input: "data: a\n\ndata: b\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "colon in value is preserved",
+			input: "data: {\"a\": \"b:c\"}\n\n",
+			want:  []SSEEvent{{Data: "{\"a\": \"b:c\"}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, _, err := parseAll(t, tt.input, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, events)
+			}
+		})
+	}
+}
+
+func TestSSEParserLastEventIDAndRetry(t *testing.T) {
+	_, parser, err := parseAll(t, "id: 1\ndata: a\n\nretry: 250\n\ndata: b\n\nid\n\n", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 空的id字段会重置last event ID
+	if parser.lastEventID != "" {
+		t.Errorf("Expected last event ID to be reset, got %q", parser.lastEventID)
+	}
+
+	if parser.retry != 250*time.Millisecond {
+		t.Errorf("Expected retry 250ms, got %v", parser.retry)
+	}
+}
+
+func TestSSEParserLargePayload(t *testing.T) {
+	payload := strings.Repeat("x", 1<<20)
+	events, _, err := parseAll(t, "data: "+payload+"\n\n", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(events) != 1 || len(events[0].Data) != len(payload) {
+		t.Errorf("Expected one 1MB event, got %d events", len(events))
+	}
+}
+
+func TestSSEParserLineTooLong(t *testing.T) {
+	_, _, err := parseAll(t, "data: "+strings.Repeat("x", 1024)+"\n\n", 512)
+	if !stderrors.Is(err, ErrSSELineTooLong) {
+		t.Errorf("Expected ErrSSELineTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apierrors "github.com/kingfs/godify/errors"
+)
+
+// recordingHandler 记录收到的事件，并在每个事件到达时回调
+type recordingHandler struct {
+	events      []SSEEvent
+	errs        []error
+	completed   bool
+	onEventHook func(event *SSEEvent)
+}
+
+func (h *recordingHandler) OnEvent(event *SSEEvent) error {
+	h.events = append(h.events, *event)
+	if h.onEventHook != nil {
+		h.onEventHook(event)
+	}
+	return nil
+}
+
+func (h *recordingHandler) OnError(err error) {
+	h.errs = append(h.errs, err)
+}
+
+func (h *recordingHandler) OnComplete() {
+	h.completed = true
+}
+
+func newTestBaseClient(baseURL string) *BaseClient {
+	return NewBaseClient(&ClientConfig{
+		BaseURL:    baseURL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	})
+}
+
+func TestStreamResponseDeliversEventsIncrementally(t *testing.T) {
+	firstReceived := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+
+		fmt.Fprint(w, "data: {\"event\": \"message\", \"answer\": \"Hel\"}\n\n")
+		flusher.Flush()
+
+		// 在客户端确认收到第一个事件之前不发送后续数据
+		select {
+		case <-firstReceived:
+		case <-time.After(3 * time.Second):
+			t.Error("Expected first event to be delivered before the stream finished")
+			return
+		}
+
+		fmt.Fprint(w, "data: {\"event\": \"message_end\"}\n\n")
+		flusher.Flush()
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	handler.onEventHook = func(event *SSEEvent) {
+		if len(handler.events) == 1 {
+			close(firstReceived)
+		}
+	}
+
+	c := newTestBaseClient(server.URL)
+	err := c.StreamResponse(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}, handler)
+	if err != nil {
+		t.Fatalf("StreamResponse failed: %v", err)
+	}
+
+	if len(handler.events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(handler.events))
+	}
+
+	if !handler.completed {
+		t.Error("Expected OnComplete to be called")
+	}
+}
+
+func TestStreamResponseContextCancellation(t *testing.T) {
+	disconnected := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"event\": \"message\", \"answer\": \"Hi\"}\n\n")
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+		close(disconnected)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := &recordingHandler{
+		onEventHook: func(event *SSEEvent) { cancel() },
+	}
+
+	c := newTestBaseClient(server.URL)
+	err := c.StreamResponse(ctx, &Request{Method: "POST", Path: "/chat-messages"}, handler)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(3 * time.Second):
+		t.Error("Expected server to observe the client disconnect")
+	}
+}
+
+func TestStreamResponseErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code": "invalid_param", "message": "query is required"}`)
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	c := newTestBaseClient(server.URL)
+	err := c.StreamResponse(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}, handler)
+	if err == nil {
+		t.Fatal("Expected error for 400 response")
+	}
+
+	if len(handler.events) != 0 {
+		t.Errorf("Expected no events, got %d", len(handler.events))
+	}
+}
+
+func TestStreamResponseReconnectsWithLastEventID(t *testing.T) {
+	var attempts int32
+	var lastEventIDs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+
+		if attempts == 1 {
+			// 模拟代理中途断开连接：发送部分事件后直接关闭TCP连接
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Failed to hijack connection: %v", err)
+				return
+			}
+			body := "retry: 10\n\nid: 1\ndata: {\"event\": \"message\", \"answer\": \"a\"}\n\nid: 2\ndata: {\"event\": \"message\", \"answer\": \"b\"}\n\n"
+			fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n", len(body), body)
+			buf.Flush()
+			conn.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "id: 2\ndata: {\"event\": \"message\", \"answer\": \"b\"}\n\n")
+		fmt.Fprint(w, "id: 3\ndata: {\"event\": \"message_end\"}\n\n")
+	}))
+	defer server.Close()
+
+	var reconnects []string
+	c := NewBaseClient(&ClientConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		StreamReconnect: &StreamReconnectConfig{
+			MaxAttempts:   2,
+			RetryInterval: time.Minute,
+			OnReconnect: func(attempt int, lastEventID string, err error) {
+				reconnects = append(reconnects, lastEventID)
+			},
+		},
+	})
+
+	handler := &recordingHandler{}
+	start := time.Now()
+	err := c.StreamResponse(context.Background(), &Request{Method: "GET", Path: "/workflows/stream"}, handler)
+	if err != nil {
+		t.Fatalf("StreamResponse failed: %v", err)
+	}
+
+	// 服务端下发的retry优先于配置的RetryInterval
+	if time.Since(start) > 10*time.Second {
+		t.Error("Expected server retry interval to be respected")
+	}
+
+	var ids []string
+	for _, event := range handler.events {
+		ids = append(ids, event.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Expected events [1 2 3] without duplicates, got %v", ids)
+	}
+
+	if len(reconnects) != 1 || reconnects[0] != "2" {
+		t.Errorf("Expected one reconnect after event 2, got %v", reconnects)
+	}
+
+	if len(lastEventIDs) != 2 || lastEventIDs[1] != "2" {
+		t.Errorf("Expected Last-Event-ID '2' on reconnect, got %v", lastEventIDs)
+	}
+
+	if len(handler.errs) != 0 || !handler.completed {
+		t.Errorf("Expected clean completion, got errors %v", handler.errs)
+	}
+}
+
+func TestStreamResponseWithoutReconnectReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack connection: %v", err)
+			return
+		}
+		body := "data: {\"event\": \"message\"}\n\n"
+		fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n", len(body), body)
+		buf.Flush()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	c := newTestBaseClient(server.URL)
+	err := c.StreamResponse(context.Background(), &Request{Method: "GET", Path: "/stream"}, handler)
+	if err == nil {
+		t.Fatal("Expected error for interrupted stream")
+	}
+
+	if len(handler.events) != 1 || len(handler.errs) != 1 {
+		t.Errorf("Expected 1 event and 1 error, got %d events and %v", len(handler.events), handler.errs)
+	}
+
+	var streamErr *apierrors.StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Expected *errors.StreamError, got %T: %v", err, err)
+	}
+	if streamErr.LastEvent != "message" || streamErr.EventCount != 1 || streamErr.Path != "/stream" || streamErr.RequestID == "" {
+		t.Errorf("Unexpected stream error: %+v", streamErr)
+	}
+}
+
+func TestStreamResponseReconnectRequiresResumableRequest(t *testing.T) {
+	for _, resumable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("resumable=%v", resumable), func(t *testing.T) {
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprintf(w, "id: %d\ndata: {\"event\": \"message\"}\n\n", attempts)
+				if attempts > 1 {
+					fmt.Fprint(w, "id: 9\ndata: {\"event\": \"message_end\"}\n\n")
+				}
+			}))
+			defer server.Close()
+
+			c := NewBaseClient(&ClientConfig{
+				BaseURL:         server.URL,
+				StreamReconnect: &StreamReconnectConfig{MaxAttempts: 2, RetryInterval: time.Millisecond},
+			})
+			req := &Request{Method: "POST", Path: "/chat-messages", Resumable: resumable}
+			err := c.StreamResponse(context.Background(), req, &recordingHandler{})
+
+			if resumable {
+				if err != nil || attempts != 2 {
+					t.Errorf("Expected opt-in request to reconnect once, got %d attempts, %v", attempts, err)
+				}
+				return
+			}
+			if err == nil || attempts != 1 {
+				t.Errorf("Expected POST not to be resent, got %d attempts, %v", attempts, err)
+			}
+		})
+	}
+}
+
+func TestStreamResponseEOFBeforeTerminalEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"event\": \"workflow_started\"}\n\n")
+		fmt.Fprint(w, "data: {\"event\": \"node_started\"}\n\n")
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	c := newTestBaseClient(server.URL)
+	err := c.StreamResponse(context.Background(), &Request{Method: "POST", Path: "/workflows/run"}, handler)
+
+	var streamErr *apierrors.StreamError
+	if !errors.As(err, &streamErr) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected *errors.StreamError wrapping io.ErrUnexpectedEOF, got %T: %v", err, err)
+	}
+	if streamErr.LastEvent != "node_started" || streamErr.EventCount != 2 {
+		t.Errorf("Unexpected stream error: %+v", streamErr)
+	}
+	if len(handler.errs) != 1 || !handler.completed {
+		t.Errorf("Expected OnError and OnComplete, got errors %v", handler.errs)
+	}
+}
+
+func TestStreamStateSeenIsBounded(t *testing.T) {
+	state := &streamState{seen: make(map[string]struct{})}
+	for i := 0; i < maxSeenEventIDs+10; i++ {
+		state.markSeen(fmt.Sprint(i))
+	}
+
+	if len(state.seen) != maxSeenEventIDs || len(state.seenOrder) != maxSeenEventIDs {
+		t.Errorf("Expected %d tracked IDs, got %d", maxSeenEventIDs, len(state.seen))
+	}
+	if _, ok := state.seen["0"]; ok {
+		t.Error("Expected oldest ID to be evicted")
+	}
+	if _, ok := state.seen[fmt.Sprint(maxSeenEventIDs+9)]; !ok {
+		t.Error("Expected newest ID to be tracked")
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"iter"
+
+	"github.com/kingfs/godify/models"
+)
+
+// StreamResult 通道形式的流式结果，Event与Err二者只有一个非空
+type StreamResult struct {
+	Event models.StreamEvent
+	Err   error
+}
+
+// StreamEvents 以迭代器形式返回类型化流式事件
+//
+// 收到message_end后迭代结束；error事件以 *errors.APIError 形式作为错误返回。
+// 提前跳出循环会立即关闭底层连接。
+func (c *BaseClient) StreamEvents(ctx context.Context, req *Request) iter.Seq2[models.StreamEvent, error] {
+	return func(yield func(models.StreamEvent, error) bool) {
+		handler := &iterHandler{yield: yield}
+		if err := c.StreamResponse(ctx, req, handler); err != nil && !handler.stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+// StreamChan 以通道形式返回类型化流式事件
+//
+// 通道无缓冲，消费者处理较慢时会暂停读取响应体；流结束、出错或ctx取消后通道关闭。
+// 调用方需读取通道直到关闭，或取消ctx以释放后台goroutine和连接。
+func (c *BaseClient) StreamChan(ctx context.Context, req *Request) <-chan StreamResult {
+	ch := make(chan StreamResult)
+
+	go func() {
+		defer close(ch)
+		for event, err := range c.StreamEvents(ctx, req) {
+			select {
+			case ch <- StreamResult{Event: event, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// iterHandler 将SSE事件转发给迭代器的yield函数
+type iterHandler struct {
+	yield   func(models.StreamEvent, error) bool
+	stopped bool
+}
+
+// OnEvent 处理SSE事件
+func (h *iterHandler) OnEvent(event *SSEEvent) error {
+	if event.Data == "" {
+		return nil
+	}
+
+	typed, err := ParseStreamEvent(event)
+	if err != nil {
+		return err
+	}
+
+	if errEvent, ok := typed.(*models.ErrorEvent); ok {
+		return StreamErrorToAPIError(errEvent)
+	}
+
+	if !h.yield(typed, nil) {
+		h.stopped = true
+		return ErrStopStream
+	}
+
+	if typed.EventType() == models.StreamEventMessageEnd {
+		h.stopped = true
+		return ErrStopStream
+	}
+
+	return nil
+}
+
+// OnError 错误通过迭代器返回，这里无需处理
+func (h *iterHandler) OnError(err error) {}
+
+// OnComplete 完成时无需处理
+func (h *iterHandler) OnComplete() {}
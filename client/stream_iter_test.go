@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kingfs/godify/errors"
+	"github.com/kingfs/godify/models"
+)
+
+func TestStreamEventsStopsAtMessageEnd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"event\": \"message\", \"answer\": \"Hi\"}\n\n")
+		fmt.Fprint(w, "data: {\"event\": \"message_end\", \"message_id\": \"msg-1\"}\n\n")
+		fmt.Fprint(w, "data: {\"event\": \"message\", \"answer\": \"ignored\"}\n\n")
+	}))
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL)
+	var types []models.StreamEventType
+	for event, err := range c.StreamEvents(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}) {
+		if err != nil {
+			t.Fatalf("Unexpected stream error: %v", err)
+		}
+		types = append(types, event.EventType())
+	}
+
+	if len(types) != 2 || types[1] != models.StreamEventMessageEnd {
+		t.Errorf("Expected [message message_end], got %v", types)
+	}
+}
+
+func TestStreamEventsErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"event\": \"error\", \"status\": 500, \"code\": \"completion_request_error\", \"message\": \"boom\"}\n\n")
+	}))
+	defer server.Close()
+
+	c := newTestBaseClient(server.URL)
+	var lastErr error
+	for _, err := range c.StreamEvents(context.Background(), &Request{Method: "POST", Path: "/chat-messages"}) {
+		lastErr = err
+	}
+
+	apiErr := errors.GetAPIError(lastErr)
+	if apiErr == nil || apiErr.Code != "completion_request_error" {
+		t.Errorf("Expected completion_request_error API error, got %v", lastErr)
+	}
+}
+
+func TestStreamChanClosesOnContextCancel(t *testing.T) {
+	disconnected := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(disconnected)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				fmt.Fprint(w, "data: {\"event\": \"ping\"}\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newTestBaseClient(server.URL)
+	ch := c.StreamChan(ctx, &Request{Method: "POST", Path: "/chat-messages"})
+
+	result := <-ch
+	if result.Err != nil || result.Event.EventType() != models.StreamEventPing {
+		t.Fatalf("Expected ping event, got %+v", result)
+	}
+
+	// 消费者停止读取并取消ctx，通道必须关闭
+	cancel()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				select {
+				case <-disconnected:
+				case <-time.After(3 * time.Second):
+					t.Error("Expected server to observe the client disconnect")
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected channel to be closed after context cancellation")
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kingfs/godify/errors"
+	"github.com/kingfs/godify/models"
+)
+
+// ParseStreamEvent 将SSE事件解析为类型化的Dify流式事件
+//
+// Dify将事件类型放在data的event字段中，若缺失则使用SSE的event字段。
+// 未识别的事件类型返回 *models.UnknownEvent。
+func ParseStreamEvent(event *SSEEvent) (models.StreamEvent, error) {
+	data := []byte(event.Data)
+
+	var base models.StreamEventBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("failed to parse stream event: %w", err)
+	}
+	if base.Event == "" {
+		base.Event = models.StreamEventType(event.Event)
+	}
+
+	// 预先填充公共字段，data中缺失event字段时保留SSE的事件类型
+	var result models.StreamEvent
+	switch base.Event {
+	case models.StreamEventMessage:
+		result = &models.MessageEvent{StreamEventBase: base}
+	case models.StreamEventAgentMessage:
+		result = &models.AgentMessageEvent{MessageEvent: models.MessageEvent{StreamEventBase: base}}
+	case models.StreamEventAgentThought:
+		result = &models.AgentThoughtEvent{StreamEventBase: base}
+	case models.StreamEventMessageFile:
+		result = &models.MessageFileEvent{StreamEventBase: base}
+	case models.StreamEventMessageEnd:
+		result = &models.MessageEndEvent{StreamEventBase: base}
+	case models.StreamEventMessageReplace:
+		result = &models.MessageReplaceEvent{StreamEventBase: base}
+	case models.StreamEventTTSMessage, models.StreamEventTTSMessageEnd:
+		result = &models.TTSMessageEvent{StreamEventBase: base}
+	case models.StreamEventWorkflowStarted:
+		result = &models.WorkflowStartedEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventNodeStarted:
+		result = &models.NodeStartedEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventNodeFinished:
+		result = &models.NodeFinishedEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventWorkflowFinished:
+		result = &models.WorkflowFinishedEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventTextChunk:
+		result = &models.TextChunkEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventIterationStarted, models.StreamEventIterationNext, models.StreamEventIterationDone:
+		result = &models.IterationEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventLoopStarted, models.StreamEventLoopNext, models.StreamEventLoopDone:
+		result = &models.LoopEvent{WorkflowEventBase: models.WorkflowEventBase{StreamEventBase: base}}
+	case models.StreamEventError:
+		result = &models.ErrorEvent{StreamEventBase: base}
+	case models.StreamEventPing:
+		return &models.PingEvent{StreamEventBase: base}, nil
+	default:
+		return &models.UnknownEvent{StreamEventBase: base, Raw: json.RawMessage(data)}, nil
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", base.Event, err)
+	}
+	return result, nil
+}
+
+// TypedSSEHandler 按事件类型分发的SSE处理器
+//
+// 未设置回调的事件将被忽略；收到error事件且未设置OnStreamError时，
+// 返回 *errors.APIError 并终止流。
+type TypedSSEHandler struct {
+	OnMessage          func(event *models.MessageEvent) error
+	OnAgentMessage     func(event *models.AgentMessageEvent) error
+	OnAgentThought     func(event *models.AgentThoughtEvent) error
+	OnMessageFile      func(event *models.MessageFileEvent) error
+	OnMessageEnd       func(event *models.MessageEndEvent) error
+	OnMessageReplace   func(event *models.MessageReplaceEvent) error
+	OnTTSMessage       func(event *models.TTSMessageEvent) error
+	OnTTSMessageEnd    func(event *models.TTSMessageEvent) error
+	OnWorkflowStarted  func(event *models.WorkflowStartedEvent) error
+	OnNodeStarted      func(event *models.NodeStartedEvent) error
+	OnNodeFinished     func(event *models.NodeFinishedEvent) error
+	OnWorkflowFinished func(event *models.WorkflowFinishedEvent) error
+	OnTextChunk        func(event *models.TextChunkEvent) error
+	// OnIteration 与 OnLoop 接收全部阶段的事件，可通过EventType()区分
+	OnIteration   func(event *models.IterationEvent) error
+	OnLoop        func(event *models.LoopEvent) error
+	OnStreamError func(event *models.ErrorEvent) error
+	OnPing        func(event *models.PingEvent) error
+	OnUnknown     func(event *models.UnknownEvent) error
+
+	OnErrorFunc    func(err error)
+	OnCompleteFunc func()
+}
+
+// OnEvent 处理SSE事件
+func (h *TypedSSEHandler) OnEvent(event *SSEEvent) error {
+	if event.Data == "" {
+		return nil
+	}
+
+	typed, err := ParseStreamEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return h.dispatch(typed)
+}
+
+// dispatch 将类型化事件分发到对应回调
+func (h *TypedSSEHandler) dispatch(event models.StreamEvent) error {
+	switch e := event.(type) {
+	case *models.MessageEvent:
+		if h.OnMessage != nil {
+			return h.OnMessage(e)
+		}
+	case *models.AgentMessageEvent:
+		if h.OnAgentMessage != nil {
+			return h.OnAgentMessage(e)
+		}
+	case *models.AgentThoughtEvent:
+		if h.OnAgentThought != nil {
+			return h.OnAgentThought(e)
+		}
+	case *models.MessageFileEvent:
+		if h.OnMessageFile != nil {
+			return h.OnMessageFile(e)
+		}
+	case *models.MessageEndEvent:
+		if h.OnMessageEnd != nil {
+			return h.OnMessageEnd(e)
+		}
+	case *models.MessageReplaceEvent:
+		if h.OnMessageReplace != nil {
+			return h.OnMessageReplace(e)
+		}
+	case *models.TTSMessageEvent:
+		if e.Event == models.StreamEventTTSMessageEnd {
+			if h.OnTTSMessageEnd != nil {
+				return h.OnTTSMessageEnd(e)
+			}
+		} else if h.OnTTSMessage != nil {
+			return h.OnTTSMessage(e)
+		}
+	case *models.WorkflowStartedEvent:
+		if h.OnWorkflowStarted != nil {
+			return h.OnWorkflowStarted(e)
+		}
+	case *models.NodeStartedEvent:
+		if h.OnNodeStarted != nil {
+			return h.OnNodeStarted(e)
+		}
+	case *models.NodeFinishedEvent:
+		if h.OnNodeFinished != nil {
+			return h.OnNodeFinished(e)
+		}
+	case *models.WorkflowFinishedEvent:
+		if h.OnWorkflowFinished != nil {
+			return h.OnWorkflowFinished(e)
+		}
+	case *models.TextChunkEvent:
+		if h.OnTextChunk != nil {
+			return h.OnTextChunk(e)
+		}
+	case *models.IterationEvent:
+		if h.OnIteration != nil {
+			return h.OnIteration(e)
+		}
+	case *models.LoopEvent:
+		if h.OnLoop != nil {
+			return h.OnLoop(e)
+		}
+	case *models.ErrorEvent:
+		if h.OnStreamError != nil {
+			return h.OnStreamError(e)
+		}
+		return StreamErrorToAPIError(e)
+	case *models.PingEvent:
+		if h.OnPing != nil {
+			return h.OnPing(e)
+		}
+	case *models.UnknownEvent:
+		if h.OnUnknown != nil {
+			return h.OnUnknown(e)
+		}
+	}
+	return nil
+}
+
+// OnError 处理错误
+func (h *TypedSSEHandler) OnError(err error) {
+	if h.OnErrorFunc != nil {
+		h.OnErrorFunc(err)
+	}
+}
+
+// OnComplete 处理完成
+func (h *TypedSSEHandler) OnComplete() {
+	if h.OnCompleteFunc != nil {
+		h.OnCompleteFunc()
+	}
+}
+
+// StreamErrorToAPIError 将流中的error事件转换为API错误
+func StreamErrorToAPIError(event *models.ErrorEvent) *errors.APIError {
+	return &errors.APIError{
+		StatusCode: event.Status,
+		Code:       event.Code,
+		Message:    event.Message,
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/kingfs/godify/errors"
+	"github.com/kingfs/godify/models"
+)
+
+func TestParseStreamEvent(t *testing.T) {
+	event, err := ParseStreamEvent(&SSEEvent{Data: `{
+		"event": "message_end",
+		"task_id": "task-1",
+		"message_id": "msg-1",
+		"conversation_id": "conv-1",
+		"metadata": {
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15, "total_price": "0.0001", "currency": "USD", "latency": 1.2},
+			"retriever_resources": [{"position": 1, "dataset_id": "ds-1", "segment_id": "seg-1", "score": 0.9, "content": "hello"}]
+		}
+	}`})
+	if err != nil {
+		t.Fatalf("ParseStreamEvent failed: %v", err)
+	}
+
+	end, ok := event.(*models.MessageEndEvent)
+	if !ok {
+		t.Fatalf("Expected *models.MessageEndEvent, got %T", event)
+	}
+
+	if end.ConversationID != "conv-1" {
+		t.Errorf("Expected conversation ID 'conv-1', got %s", end.ConversationID)
+	}
+
+	if end.Metadata.Usage == nil || end.Metadata.Usage.TotalTokens != 15 {
+		t.Errorf("Expected usage total tokens 15, got %+v", end.Metadata.Usage)
+	}
+
+	if end.Metadata.Usage.TotalPrice.String() != "0.0001" {
+		t.Errorf("Expected total price '0.0001', got %s", end.Metadata.Usage.TotalPrice)
+	}
+
+	if len(end.Metadata.RetrieverResources) != 1 || end.Metadata.RetrieverResources[0].SegmentID != "seg-1" {
+		t.Errorf("Expected one retriever resource for seg-1, got %+v", end.Metadata.RetrieverResources)
+	}
+}
+
+func TestParseStreamEventFallsBackToSSEEventName(t *testing.T) {
+	event, err := ParseStreamEvent(&SSEEvent{Event: "node_finished", Data: `{"workflow_run_id": "run-1", "data": {"node_id": "llm", "status": "succeeded", "execution_metadata": {"total_tokens": 42}}}`})
+	if err != nil {
+		t.Fatalf("ParseStreamEvent failed: %v", err)
+	}
+
+	node, ok := event.(*models.NodeFinishedEvent)
+	if !ok {
+		t.Fatalf("Expected *models.NodeFinishedEvent, got %T", event)
+	}
+
+	if node.EventType() != models.StreamEventNodeFinished {
+		t.Errorf("Expected event type 'node_finished', got %s", node.EventType())
+	}
+
+	if node.WorkflowRunID != "run-1" || node.Data.NodeID != "llm" {
+		t.Errorf("Unexpected node event: %+v", node)
+	}
+
+	if node.Data.ExecutionMetadata == nil || node.Data.ExecutionMetadata.TotalTokens != 42 {
+		t.Errorf("Expected execution metadata total tokens 42, got %+v", node.Data.ExecutionMetadata)
+	}
+}
+
+func TestParseStreamEventUnknown(t *testing.T) {
+	event, err := ParseStreamEvent(&SSEEvent{Data: `{"event": "something_new", "foo": "bar"}`})
+	if err != nil {
+		t.Fatalf("ParseStreamEvent failed: %v", err)
+	}
+
+	unknown, ok := event.(*models.UnknownEvent)
+	if !ok {
+		t.Fatalf("Expected *models.UnknownEvent, got %T", event)
+	}
+
+	if string(unknown.Raw) != `{"event": "something_new", "foo": "bar"}` {
+		t.Errorf("Expected raw data to be preserved, got %s", unknown.Raw)
+	}
+}
+
+func TestTypedSSEHandlerDispatch(t *testing.T) {
+	var answer string
+	var thoughts int
+
+	handler := &TypedSSEHandler{
+		OnMessage: func(event *models.MessageEvent) error {
+			answer += event.Answer
+			return nil
+		},
+		OnAgentMessage: func(event *models.AgentMessageEvent) error {
+			answer += event.Answer
+			return nil
+		},
+		OnAgentThought: func(event *models.AgentThoughtEvent) error {
+			thoughts++
+			return nil
+		},
+	}
+
+	events := []string{
+		`{"event": "message", "answer": "Hello"}`,
+		`{"event": "agent_thought", "id": "t-1", "thought": "thinking"}`,
+		`{"event": "agent_message", "answer": ", world"}`,
+		`{"event": "ping"}`,
+	}
+	for _, data := range events {
+		if err := handler.OnEvent(&SSEEvent{Data: data}); err != nil {
+			t.Fatalf("OnEvent failed: %v", err)
+		}
+	}
+
+	if answer != "Hello, world" {
+		t.Errorf("Expected answer 'Hello, world', got %s", answer)
+	}
+
+	if thoughts != 1 {
+		t.Errorf("Expected 1 agent thought, got %d", thoughts)
+	}
+}
+
+func TestTypedSSEHandlerErrorEvent(t *testing.T) {
+	handler := &TypedSSEHandler{}
+
+	err := handler.OnEvent(&SSEEvent{Data: `{"event": "error", "status": 400, "code": "invoke_rate_limit", "message": "rate limited"}`})
+	apiErr := errors.GetAPIError(err)
+	if apiErr == nil {
+		t.Fatalf("Expected API error, got %v", err)
+	}
+
+	if apiErr.Code != "invoke_rate_limit" || apiErr.StatusCode != 400 {
+		t.Errorf("Unexpected API error: %+v", apiErr)
+	}
+}
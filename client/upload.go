@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// UploadOptions 流式上传选项
+type UploadOptions struct {
+	// Size 文件大小，大于0时设置Content-Length并作为进度回调的总量
+	Size int64
+	// ContentType 文件的Content-Type，为空时按扩展名推断，仍无法确定时探测文件头
+	ContentType string
+	// ExtraFields 额外的表单字段
+	ExtraFields map[string]string
+	// Headers 额外的请求头
+	Headers map[string]string
+	// Progress 上传进度回调，total未知时为-1
+	Progress func(written, total int64)
+}
+
+// WithExtraFields 复制上传选项并合并表单字段，fields优先于ExtraFields中的同名字段
+//
+// 用于附加接口必需的字段，调用方无法覆盖；o可为nil，原选项不会被修改。
+func (o *UploadOptions) WithExtraFields(fields map[string]string) *UploadOptions {
+	merged := UploadOptions{}
+	if o != nil {
+		merged = *o
+	}
+	extraFields := make(map[string]string, len(merged.ExtraFields)+len(fields))
+	for k, v := range merged.ExtraFields {
+		extraFields[k] = v
+	}
+	for k, v := range fields {
+		extraFields[k] = v
+	}
+	merged.ExtraFields = extraFields
+	return &merged
+}
+
+// UploadFileReader 以流式方式上传文件
+//
+// 文件内容通过管道边读边写入multipart请求体，不会整体加载到内存。
+// 由于请求体只能读取一次，该请求不会自动重试。
+func (c *BaseClient) UploadFileReader(ctx context.Context, path string, fieldName string, filename string, r io.Reader, opts *UploadOptions) (*Response, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+
+	br := bufio.NewReader(r)
+	contentType := detectContentType(filename, opts.ContentType, br)
+
+	pr, pw := io.Pipe()
+	// 请求结束后关闭读端，确保写入协程退出
+	defer pr.Close()
+
+	writer := multipart.NewWriter(pw)
+
+	var contentLength int64
+	if opts.Size > 0 {
+		overhead, err := multipartOverhead(writer.Boundary(), fieldName, filename, contentType, opts.ExtraFields)
+		if err != nil {
+			return nil, err
+		}
+		contentLength = overhead + opts.Size
+	}
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fieldName, filename, contentType, opts.ExtraFields, &progressReader{
+			r:        br,
+			total:    opts.Size,
+			progress: opts.Progress,
+		}))
+	}()
+
+	headers := make(map[string]string, len(opts.Headers)+1)
+	for k, v := range opts.Headers {
+		headers[k] = v
+	}
+	headers["Content-Type"] = writer.FormDataContentType()
+
+	req := &Request{
+		Method:        "POST",
+		Path:          path,
+		Headers:       headers,
+		Body:          pr,
+		ContentLength: contentLength,
+	}
+
+	return c.Do(ctx, req)
+}
+
+// writeMultipart 写入multipart请求体，额外字段在文件之前写入
+func writeMultipart(writer *multipart.Writer, fieldName, filename, contentType string, extraFields map[string]string, file io.Reader) error {
+	for key, value := range extraFields {
+		if err := writer.WriteField(key, value); err != nil {
+			return fmt.Errorf("failed to write field %s: %w", key, err)
+		}
+	}
+
+	part, err := writer.CreatePart(filePartHeader(fieldName, filename, contentType))
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+
+	if file != nil {
+		if _, err := io.Copy(part, file); err != nil {
+			return fmt.Errorf("failed to write file data: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return nil
+}
+
+// multipartOverhead 计算除文件内容外multipart请求体的长度
+func multipartOverhead(boundary, fieldName, filename, contentType string, extraFields map[string]string) (int64, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, fmt.Errorf("failed to set multipart boundary: %w", err)
+	}
+	if err := writeMultipart(writer, fieldName, filename, contentType, extraFields, nil); err != nil {
+		return 0, err
+	}
+	return int64(buf.Len()), nil
+}
+
+// filePartHeader 构建文件字段的头部
+func filePartHeader(fieldName, filename, contentType string) textproto.MIMEHeader {
+	escape := strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escape.Replace(fieldName), escape.Replace(filename)))
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// detectContentType 确定文件的Content-Type
+func detectContentType(filename, contentType string, br *bufio.Reader) string {
+	if contentType != "" {
+		return contentType
+	}
+	// 扩展名只能得到通用类型时继续探测文件头
+	if byExt := mime.TypeByExtension(filepath.Ext(filename)); byExt != "" && byExt != "application/octet-stream" {
+		return byExt
+	}
+
+	// Peek不消耗数据，读取不足512字节时返回已有内容
+	head, _ := br.Peek(512)
+	if len(head) == 0 {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(head)
+}
+
+// progressReader 统计已读取字节数并回调进度
+type progressReader struct {
+	r        io.Reader
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+// Read 读取数据
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 && p.progress != nil {
+		p.written += int64(n)
+		total := p.total
+		if total <= 0 {
+			total = -1
+		}
+		p.progress(p.written, total)
+	}
+	return n, err
+}
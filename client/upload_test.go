@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadFileReaderKnownSize(t *testing.T) {
+	content := strings.Repeat("%PDF-1.4 data ", 1024)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= int64(len(content)) {
+			t.Errorf("Expected Content-Length larger than file size, got %d", r.ContentLength)
+		}
+
+		if source := r.FormValue("source"); source != "datasets" {
+			t.Errorf("Expected source 'datasets', got %s", source)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Expected file field: %v", err)
+			return
+		}
+		defer file.Close()
+
+		if ct := header.Header.Get("Content-Type"); ct != "application/pdf" {
+			t.Errorf("Expected detected Content-Type 'application/pdf', got %s", ct)
+		}
+
+		data, _ := io.ReadAll(file)
+		if string(data) != content {
+			t.Errorf("Expected %d bytes of file data, got %d", len(content), len(data))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "file-1"}`))
+	}))
+	defer server.Close()
+
+	var written, total int64
+	c := newTestBaseClient(server.URL)
+	resp, err := c.UploadFileReader(context.Background(), "/files/upload", "file", "report.bin", strings.NewReader(content), &UploadOptions{
+		Size:        int64(len(content)),
+		ExtraFields: map[string]string{"source": "datasets"},
+		Progress: func(w, t int64) {
+			written, total = w, t
+		},
+	})
+	if err != nil {
+		t.Fatalf("UploadFileReader failed: %v", err)
+	}
+
+	if string(resp.Body) != `{"id": "file-1"}` {
+		t.Errorf("Unexpected response body: %s", resp.Body)
+	}
+
+	if written != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("Expected final progress %d/%d, got %d/%d", len(content), len(content), written, total)
+	}
+}
+
+func TestUploadFileReaderUnknownSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("Expected chunked request body, got Content-Length %d", r.ContentLength)
+		}
+
+		file, header, err := r.FormFile("pkg")
+		if err != nil {
+			t.Errorf("Expected pkg field: %v", err)
+			return
+		}
+		defer file.Close()
+
+		if ct := header.Header.Get("Content-Type"); ct != "application/zip" {
+			t.Errorf("Expected Content-Type 'application/zip', got %s", ct)
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var total int64
+	c := newTestBaseClient(server.URL)
+	_, err := c.UploadFileReader(context.Background(), "/plugin/upload/pkg", "pkg", "plugin.difypkg", strings.NewReader("PK\x03\x04"), &UploadOptions{
+		ContentType: "application/zip",
+		Progress:    func(_, t int64) { total = t },
+	})
+	if err != nil {
+		t.Fatalf("UploadFileReader failed: %v", err)
+	}
+
+	if total != -1 {
+		t.Errorf("Expected unknown total -1, got %d", total)
+	}
+}
+
+func TestUploadOptionsWithExtraFields(t *testing.T) {
+	opts := &UploadOptions{Size: 10, ExtraFields: map[string]string{"user": "spoofed", "tag": "docs"}}
+	merged := opts.WithExtraFields(map[string]string{"user": "alice"})
+
+	if merged.ExtraFields["user"] != "alice" || merged.ExtraFields["tag"] != "docs" || merged.Size != 10 {
+		t.Errorf("Expected required field to win over caller fields, got %+v", merged)
+	}
+	if opts.ExtraFields["user"] != "spoofed" {
+		t.Error("Expected caller options to be left unchanged")
+	}
+
+	var none *UploadOptions
+	if fields := none.WithExtraFields(map[string]string{"source": "web"}).ExtraFields; fields["source"] != "web" {
+		t.Errorf("Expected nil options to be supported, got %v", fields)
+	}
+}
@@ -2,6 +2,8 @@ package console
 
 import (
 	"context"
+	"iter"
+	"net/url"
 	"strconv"
 
 	"github.com/kingfs/godify/client"
@@ -20,18 +22,18 @@ import (
 
 // GetApps 获取应用列表
 func (c *Client) GetApps(ctx context.Context, page, limit int, mode, name string, tagIDs []string, isCreatedByMe *bool) (*models.ConsoleAppListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if page > 0 {
-		query["page"] = strconv.Itoa(page)
+		query.Set("page", strconv.Itoa(page))
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if mode != "" {
-		query["mode"] = mode
+		query.Set("mode", mode)
 	}
 	if name != "" {
-		query["name"] = name
+		query.Set("name", name)
 	}
 	if len(tagIDs) > 0 {
 		// tagIDs需要用逗号分隔
@@ -42,10 +44,10 @@ func (c *Client) GetApps(ctx context.Context, page, limit int, mode, name string
 			}
 			tagIDsStr += id
 		}
-		query["tag_ids"] = tagIDsStr
+		query.Set("tag_ids", tagIDsStr)
 	}
 	if isCreatedByMe != nil {
-		query["is_created_by_me"] = strconv.FormatBool(*isCreatedByMe)
+		query.Set("is_created_by_me", strconv.FormatBool(*isCreatedByMe))
 	}
 
 	req := &client.Request{
@@ -62,6 +64,17 @@ func (c *Client) GetApps(ctx context.Context, page, limit int, mode, name string
 	return &result, nil
 }
 
+// AllApps 遍历全部应用，自动翻页
+func (c *Client) AllApps(ctx context.Context, mode, name string, tagIDs []string, isCreatedByMe *bool, opts *client.PagerOptions) iter.Seq2[models.ConsoleApp, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.ConsoleApp], error) {
+		resp, err := c.GetApps(ctx, page, limit, mode, name, tagIDs, isCreatedByMe)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.ConsoleApp]{Items: resp.Data, HasMore: resp.HasMore}, nil
+	}, opts).Items(ctx)
+}
+
 // CreateApp 创建应用
 func (c *Client) CreateApp(ctx context.Context, req *models.CreateAppRequest) (*models.ConsoleApp, error) {
 	httpReq := &client.Request{
@@ -126,8 +139,8 @@ func (c *Client) CopyApp(ctx context.Context, appID string, req *models.CopyAppR
 
 // ExportApp 导出应用
 func (c *Client) ExportApp(ctx context.Context, appID string, includeSecret bool) (*models.AppExportResponse, error) {
-	query := map[string]string{
-		"include_secret": strconv.FormatBool(includeSecret),
+	query := url.Values{
+		"include_secret": {strconv.FormatBool(includeSecret)},
 	}
 
 	req := &client.Request{
@@ -218,12 +231,12 @@ func (c *Client) UpdateAppTrace(ctx context.Context, appID string, req *models.U
 }
 
 func (c *Client) GetAppsChatMessageList(ctx context.Context, appID string, conversationID string, firstID, limit *int) (*models.AppsChatMessageListApiResponse, error) {
-	reqQuery := map[string]string{"conversation_id": conversationID}
+	reqQuery := url.Values{"conversation_id": {conversationID}}
 	if firstID != nil {
-		reqQuery["first_id"] = strconv.Itoa(*firstID)
+		reqQuery.Set("first_id", strconv.Itoa(*firstID))
 	}
 	if limit != nil {
-		reqQuery["limit"] = strconv.Itoa(*limit)
+		reqQuery.Set("limit", strconv.Itoa(*limit))
 	}
 	req := &client.Request{
 		Method: "GET",
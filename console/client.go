@@ -14,42 +14,50 @@ type Client struct {
 }
 
 // NewClient 创建Console API客户端
-// 注意: Console API通常需要session认证，这里使用Bearer token作为临时方案
-func NewClient(accessToken, baseURL string) *Client {
+// accessToken 以Cookie方式发送，登录后可通过WithCookies设置csrf_token等会话信息
+// 默认超时30秒、重试3次并跳过证书校验，可通过opts修改
+func NewClient(accessToken, baseURL string, opts ...client.Option) *Client {
 	config := &client.ClientConfig{
-		BaseURL:  baseURL + "/console/api",
-		AuthType: client.AuthTypeBearer,
-		// Token:      accessToken,
-		Timeout:    30 * time.Second,
-		SkipTLS:    true,
-		MaxRetries: 3,
-		Cookies: map[string]string{
-			"access_token": accessToken,
-		},
+		BaseURL:       baseURL + "/console/api",
+		Timeout:       30 * time.Second,
+		SkipTLS:       true,
+		MaxRetries:    3,
+		Authenticator: client.NewConsoleAuth(accessToken),
 	}
 
 	return &Client{
-		baseClient: client.NewBaseClient(config),
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
 // NewClientWithSession 使用Session Cookie创建Console API客户端
-func NewClientWithSession(sessionCookie, baseURL string) *Client {
+func NewClientWithSession(sessionCookie, baseURL string, opts ...client.Option) *Client {
+	auth := client.NewConsoleAuth("")
+	auth.SetCookies(map[string]string{"session": sessionCookie})
+
 	config := &client.ClientConfig{
-		BaseURL:    baseURL + "/console/api",
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
+		BaseURL:       baseURL + "/console/api",
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		Authenticator: auth,
 	}
 
-	baseClient := client.NewBaseClient(config)
-	// 这里需要自定义HTTP客户端来处理session cookie
-	// 实际实现中需要设置Cookie头
-
 	return &Client{
-		baseClient: baseClient,
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
+// WithWorkspace 派生使用指定工作空间的客户端，与原客户端共享连接池与登录会话
+func (c *Client) WithWorkspace(workspaceID string) *Client {
+	return &Client{baseClient: c.baseClient.WithWorkspace(workspaceID)}
+}
+
 func (c *Client) WithWorkspaceID(workspaceID string) *Client {
 	c.baseClient.WithWorkspaceID(workspaceID)
 	return c
@@ -68,43 +76,104 @@ func (c *Client) WithToken(token string) *Client {
 // ============ 认证相关 ============
 
 // Login 用户登录
+// 登录成功后access_token、refresh_token与csrf_token会写回客户端，后续请求自动携带
 func (c *Client) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
 	httpReq := &client.Request{
 		Method: "POST",
-		Path:   "/login",
+		Path:   loginPath,
 		Body:   req,
 	}
 
-	var result models.LoginResponse
 	resp, err := c.baseClient.Do(ctx, httpReq)
 	if err != nil {
 		return nil, err
 	}
-	// 将resp.Cookies中的csrf_token、access_token、refresh_token赋值给result.Data.CSRFToken、result.Data.AccessToken、result.Data.RefreshToken
-	if resp.Cookies != nil {
-		result.Data.CSRFToken = resp.Cookies["csrf_token"]
-		result.Data.AccessToken = resp.Cookies["access_token"]
-		result.Data.RefreshToken = resp.Cookies["refresh_token"]
-	}
-	return &result, err
+	return c.applyLoginResponse(resp)
 }
 
-// refresh token
+// RefreshToken 使用refresh_token刷新访问令牌，新令牌会写回客户端
 func (c *Client) RefreshToken(ctx context.Context) (*models.LoginResponse, error) {
 	httpReq := &client.Request{
 		Method: "POST",
-		Path:   "/refresh-token",
+		Path:   refreshTokenPath,
+	}
+	// 旧版本Dify从请求体读取refresh_token，新版本从Cookie读取
+	if auth := c.consoleAuth(); auth != nil {
+		if refreshToken := auth.Cookie("refresh_token"); refreshToken != "" {
+			httpReq.Body = map[string]string{"refresh_token": refreshToken}
+		}
 	}
 
-	var result models.LoginResponse
 	resp, err := c.baseClient.Do(ctx, httpReq)
 	if err != nil {
 		return nil, err
 	}
+	return c.applyLoginResponse(resp)
+}
+
+const (
+	loginPath        = "/login"
+	refreshTokenPath = "/refresh-token"
+)
+
+// applyLoginResponse 解析登录/刷新响应并将令牌写回认证信息
+//
+// 旧版本Dify在响应体data中返回令牌，新版本通过Set-Cookie返回，两者都存在时以Cookie为准。
+func (c *Client) applyLoginResponse(resp *client.Response) (*models.LoginResponse, error) {
+	var result models.LoginResponse
+	if len(resp.Body) > 0 {
+		if err := resp.DecodeJSON(&result); err != nil {
+			return nil, err
+		}
+	}
 	if resp.Cookies != nil {
-		result.Data.CSRFToken = resp.Cookies["csrf_token"]
-		result.Data.AccessToken = resp.Cookies["access_token"]
-		result.Data.RefreshToken = resp.Cookies["refresh_token"]
+		if v := resp.Cookies["csrf_token"]; v != "" {
+			result.Data.CSRFToken = v
+		}
+		if v := resp.Cookies["access_token"]; v != "" {
+			result.Data.AccessToken = v
+		}
+		if v := resp.Cookies["refresh_token"]; v != "" {
+			result.Data.RefreshToken = v
+		}
+	}
+
+	c.setTokens(result.Data)
+	return &result, nil
+}
+
+// setTokens 将非空令牌写入控制台认证信息
+//
+// 控制台认证由WithWorkspace派生的客户端共享，令牌原地更新，使登录会话在派生客户端间保持一致。
+func (c *Client) setTokens(tokens models.TokenPair) {
+	cookies := tokenCookies(tokens)
+	if len(cookies) == 0 {
+		return
 	}
-	return &result, err
+	if auth := c.consoleAuth(); auth != nil {
+		auth.SetCookies(cookies)
+		return
+	}
+	c.baseClient.WithCookies(cookies)
+}
+
+// tokenCookies 将非空令牌转换为cookies
+func tokenCookies(tokens models.TokenPair) map[string]string {
+	cookies := make(map[string]string, 3)
+	if tokens.AccessToken != "" {
+		cookies["access_token"] = tokens.AccessToken
+	}
+	if tokens.RefreshToken != "" {
+		cookies["refresh_token"] = tokens.RefreshToken
+	}
+	if tokens.CSRFToken != "" {
+		cookies["csrf_token"] = tokens.CSRFToken
+	}
+	return cookies
+}
+
+// consoleAuth 返回客户端使用的控制台认证，使用其他认证方式时返回nil
+func (c *Client) consoleAuth() *client.ConsoleAuth {
+	auth, _ := c.baseClient.Authenticator().(*client.ConsoleAuth)
+	return auth
 }
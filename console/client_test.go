@@ -184,3 +184,83 @@ func TestGetDatasets(t *testing.T) {
 		t.Errorf("Expected document count 5, got %d", datasets.Data[0].DocumentCount)
 	}
 }
+
+func TestConsoleAuthHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie := r.Header.Get("Cookie"); cookie != "access_token=test-token; csrf_token=csrf-1" {
+			t.Errorf("Expected access_token and csrf_token cookies, got %q", cookie)
+		}
+		if csrf := r.Header.Get("X-CSRF-Token"); csrf != "csrf-1" {
+			t.Errorf("Expected X-CSRF-Token 'csrf-1', got %s", csrf)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %s", auth)
+		}
+		if workspace := r.Header.Get("X-Workspace-Id"); workspace != "ws-1" {
+			t.Errorf("Expected X-Workspace-Id 'ws-1', got %s", workspace)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": 1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL).
+		WithCookies(map[string]string{"csrf_token": "csrf-1"}).
+		WithWorkspaceID("ws-1")
+	if _, err := client.GetApps(context.Background(), 1, 20, "", "", nil, nil); err != nil {
+		t.Fatalf("GetApps failed: %v", err)
+	}
+}
+
+func TestWithWorkspace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie := r.Header.Get("Cookie"); cookie != "access_token=test-token" {
+			t.Errorf("Expected shared access_token cookie, got %q", cookie)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": ` + r.Header.Get("X-Workspace-Id") + `}`))
+	}))
+	defer server.Close()
+
+	base := NewClient("test-token", server.URL).WithWorkspaceID("1")
+	derived := base.WithWorkspace("2")
+
+	for expected, c := range map[int]*Client{1: base, 2: derived} {
+		apps, err := c.GetApps(context.Background(), 1, 20, "", "", nil, nil)
+		if err != nil {
+			t.Fatalf("GetApps failed: %v", err)
+		}
+		if apps.Page != expected {
+			t.Errorf("Expected workspace %d, got %d", expected, apps.Page)
+		}
+	}
+}
+
+func TestTagFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/console/api/datasets":
+			if tags := query["tag_ids"]; len(tags) != 2 || tags[0] != "tag-1" || tags[1] != "tag-2" {
+				t.Errorf("Expected repeated tag_ids [tag-1 tag-2], got %v", tags)
+			}
+		case "/console/api/apps":
+			if tags := query.Get("tag_ids"); tags != "tag-1,tag-2" {
+				t.Errorf("Expected comma separated tag_ids 'tag-1,tag-2', got %q", tags)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": 1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	tags := []string{"tag-1", "tag-2"}
+	if _, err := client.GetDatasets(context.Background(), 1, 20, "", tags, false); err != nil {
+		t.Fatalf("GetDatasets failed: %v", err)
+	}
+	if _, err := client.GetApps(context.Background(), 1, 20, "", "", tags, nil); err != nil {
+		t.Fatalf("GetApps failed: %v", err)
+	}
+}
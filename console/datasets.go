@@ -2,6 +2,8 @@ package console
 
 import (
 	"context"
+	"iter"
+	"net/url"
 	"strconv"
 
 	"github.com/kingfs/godify/client"
@@ -12,25 +14,22 @@ import (
 
 // GetDatasets 获取数据集列表
 func (c *Client) GetDatasets(ctx context.Context, page, limit int, keyword string, tagIDs []string, includeAll bool) (*models.DatasetListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if page > 0 {
-		query["page"] = strconv.Itoa(page)
+		query.Set("page", strconv.Itoa(page))
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if keyword != "" {
-		query["keyword"] = keyword
+		query.Set("keyword", keyword)
 	}
-	if len(tagIDs) > 0 {
-		// 使用多个tag_ids参数
-		for _, tagID := range tagIDs {
-			// 注意：实际实现中需要支持重复参数名
-			query["tag_ids"] = tagID
-		}
+	// 每个标签作为一个重复的tag_ids参数
+	for _, tagID := range tagIDs {
+		query.Add("tag_ids", tagID)
 	}
 	if includeAll {
-		query["include_all"] = "true"
+		query.Set("include_all", "true")
 	}
 
 	req := &client.Request{
@@ -44,6 +43,17 @@ func (c *Client) GetDatasets(ctx context.Context, page, limit int, keyword strin
 	return &result, err
 }
 
+// AllDatasets 遍历全部数据集，自动翻页
+func (c *Client) AllDatasets(ctx context.Context, keyword string, tagIDs []string, includeAll bool, opts *client.PagerOptions) iter.Seq2[models.Dataset, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.Dataset], error) {
+		resp, err := c.GetDatasets(ctx, page, limit, keyword, tagIDs, includeAll)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.Dataset]{Items: resp.Data, HasMore: resp.HasMore}, nil
+	}, opts).Items(ctx)
+}
+
 // CreateDataset 创建数据集
 func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetRequest) (*models.Dataset, error) {
 	httpReq := &client.Request{
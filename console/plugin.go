@@ -2,8 +2,9 @@ package console
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -16,9 +17,9 @@ var pluginURLPrefix string = "/workspaces/current/plugin"
 
 // PluginList 获取插件列表
 func (c *Client) GetPluginList(ctx context.Context, page, pageSize int) (*models.PluginListResponse, error) {
-	query := map[string]string{
-		"page":      strconv.Itoa(page),
-		"page_size": strconv.Itoa(pageSize),
+	query := url.Values{
+		"page":      {strconv.Itoa(page)},
+		"page_size": {strconv.Itoa(pageSize)},
 	}
 	req := &client.Request{
 		Method: "GET",
@@ -39,11 +40,24 @@ func (c *Client) UploadPluginPkg(ctx context.Context, filename string, fileData
 	if err != nil {
 		return "", err
 	}
+	return decodeUniqueIdentifier(resp)
+}
+
+// UploadPluginPkgReader 以流式方式上传插件包，适用于较大的插件包
+func (c *Client) UploadPluginPkgReader(ctx context.Context, filename string, r io.Reader, opts *client.UploadOptions) (string, error) {
+	resp, err := c.baseClient.UploadFileReader(ctx, pluginURLPrefix+"/upload/pkg", "pkg", filename, r, opts)
+	if err != nil {
+		return "", err
+	}
+	return decodeUniqueIdentifier(resp)
+}
+
+// decodeUniqueIdentifier 解析插件包上传响应中的unique_identifier
+func decodeUniqueIdentifier(resp *client.Response) (string, error) {
 	var result struct {
 		UniqueIdentifier string `json:"unique_identifier"`
 	}
-	err = json.Unmarshal(resp.Body, &result)
-	if err != nil {
+	if err := resp.DecodeJSON(&result); err != nil {
 		return "", err
 	}
 	if result.UniqueIdentifier == "" {
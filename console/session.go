@@ -0,0 +1,262 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+// TokenStore 控制台会话令牌的持久化存储
+type TokenStore interface {
+	// Load 读取保存的令牌，不存在时返回nil和nil错误
+	Load(ctx context.Context) (*models.TokenPair, error)
+	// Save 保存令牌
+	Save(ctx context.Context, tokens *models.TokenPair) error
+}
+
+// MemoryTokenStore 内存令牌存储，进程退出后丢失
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens *models.TokenPair
+}
+
+// NewMemoryTokenStore 创建内存令牌存储
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+// Load 读取令牌
+func (s *MemoryTokenStore) Load(ctx context.Context) (*models.TokenPair, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.tokens == nil {
+		return nil, nil
+	}
+	tokens := *s.tokens
+	return &tokens, nil
+}
+
+// Save 保存令牌
+func (s *MemoryTokenStore) Save(ctx context.Context, tokens *models.TokenPair) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *tokens
+	s.tokens = &saved
+	return nil
+}
+
+// FileTokenStore 以JSON文件保存令牌，文件权限为0600
+type FileTokenStore struct {
+	Path string
+}
+
+// NewFileTokenStore 创建文件令牌存储
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Load 读取令牌文件，文件不存在时返回nil
+func (s *FileTokenStore) Load(ctx context.Context) (*models.TokenPair, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens models.TokenPair
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// Save 先写入临时文件再重命名，避免中断时留下不完整的文件
+func (s *FileTokenStore) Save(ctx context.Context, tokens *models.TokenPair) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// SessionManager 控制台会话管理
+//
+// 负责登录、保存令牌，并在请求返回401时自动刷新令牌后重试一次。
+// 并发请求同时遇到401时只刷新一次；刷新失败且保存了登录凭据时重新登录。
+// 会话令牌保存在客户端及其WithWorkspace派生客户端共享的控制台认证中。
+type SessionManager struct {
+	client *Client
+	auth   *client.ConsoleAuth
+	store  TokenStore
+
+	// mu 串行化登录与刷新
+	mu          sync.Mutex
+	credentials *models.LoginRequest
+}
+
+// NewSessionManager 为客户端创建会话管理并安装自动刷新中间件
+//
+// store为nil时使用内存存储。客户端未使用控制台Cookie认证时会替换为控制台认证。
+// 之后在客户端上调用WithToken或WithCookies会得到独立的认证副本，不再由会话管理。
+func NewSessionManager(c *Client, store TokenStore) *SessionManager {
+	if store == nil {
+		store = NewMemoryTokenStore()
+	}
+
+	auth := c.consoleAuth()
+	if auth == nil {
+		auth = client.NewConsoleAuth("")
+		c.baseClient.WithAuthenticator(auth)
+	}
+
+	m := &SessionManager{
+		client: c,
+		auth:   auth,
+		store:  store,
+	}
+	c.Use(m.middleware)
+	return m
+}
+
+// Restore 从存储中恢复令牌，返回是否存在已保存的会话
+func (m *SessionManager) Restore(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens, err := m.store.Load(ctx)
+	if err != nil || tokens == nil {
+		return false, err
+	}
+	m.setTokens(*tokens)
+	return true, nil
+}
+
+// Login 登录并保存令牌，凭据会被保留用于刷新失败时重新登录
+func (m *SessionManager) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resp, err := m.client.Login(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	m.setTokens(resp.Data)
+	m.credentials = req
+	return resp, m.save(ctx)
+}
+
+// Refresh 立即刷新令牌
+func (m *SessionManager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.refresh(ctx)
+}
+
+// Tokens 返回当前使用的令牌
+func (m *SessionManager) Tokens() models.TokenPair {
+	return models.TokenPair{
+		AccessToken:  m.auth.Cookie("access_token"),
+		RefreshToken: m.auth.Cookie("refresh_token"),
+		CSRFToken:    m.auth.Cookie("csrf_token"),
+	}
+}
+
+// setTokens 将令牌写入会话共享的控制台认证
+func (m *SessionManager) setTokens(tokens models.TokenPair) {
+	if cookies := tokenCookies(tokens); len(cookies) > 0 {
+		m.auth.SetCookies(cookies)
+	}
+}
+
+// renew 在access_token仍为stale时刷新，已被其他请求刷新过则直接返回
+func (m *SessionManager) renew(ctx context.Context, stale string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current := m.auth.Cookie("access_token"); current != "" && current != stale {
+		return nil
+	}
+	return m.refresh(ctx)
+}
+
+// refresh 刷新令牌，失败时尝试使用保存的凭据重新登录，调用方需持有mu
+func (m *SessionManager) refresh(ctx context.Context) error {
+	resp, err := m.client.RefreshToken(ctx)
+	if err != nil {
+		if m.credentials == nil {
+			return err
+		}
+		if resp, err = m.client.Login(ctx, m.credentials); err != nil {
+			return err
+		}
+	}
+	m.setTokens(resp.Data)
+	return m.save(ctx)
+}
+
+// save 保存合并后的完整令牌，服务端未轮换refresh_token时保留原值
+func (m *SessionManager) save(ctx context.Context) error {
+	tokens := m.Tokens()
+	return m.store.Save(ctx, &tokens)
+}
+
+// middleware 请求返回401时刷新令牌并重试一次
+func (m *SessionManager) middleware(next client.Handler) client.Handler {
+	return func(ctx context.Context, req *client.Request) (*client.Response, error) {
+		if req.Path == loginPath || req.Path == refreshTokenPath {
+			return next(ctx, req)
+		}
+
+		// 从会话共享的认证读取请求发送时的令牌，派生客户端与原客户端使用同一份
+		stale := m.auth.Cookie("access_token")
+		resp, err := next(ctx, req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || !replayableBody(req.Body) {
+			return resp, err
+		}
+
+		// 刷新失败时返回原始的401响应
+		if err := m.renew(ctx, stale); err != nil {
+			return resp, nil
+		}
+		return next(ctx, req)
+	}
+}
+
+// replayableBody 判断请求体能否再次发送，io.Reader只能读取一次
+func replayableBody(body interface{}) bool {
+	switch body.(type) {
+	case nil, string, []byte:
+		return true
+	case io.Reader:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kingfs/godify/models"
+)
+
+// sessionServer 模拟控制台的登录、刷新与令牌过期
+type sessionServer struct {
+	mu        sync.Mutex
+	valid     string
+	issued    int
+	refreshes int
+}
+
+// expire 使当前access_token失效
+func (s *sessionServer) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = ""
+}
+
+func (s *sessionServer) issue(w http.ResponseWriter) {
+	s.issued++
+	s.valid = fmt.Sprintf("access-%d", s.issued)
+	http.SetCookie(w, &http.Cookie{Name: "access_token", Value: s.valid})
+	http.SetCookie(w, &http.Cookie{Name: "csrf_token", Value: fmt.Sprintf("csrf-%d", s.issued)})
+	if s.issued == 1 {
+		http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "refresh-1"})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"result": "success"}`))
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/console/api/login":
+		s.issue(w)
+	case "/console/api/refresh-token":
+		if cookie, err := r.Cookie("refresh_token"); err != nil || cookie.Value != "refresh-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code": "unauthorized", "message": "invalid refresh token"}`))
+			return
+		}
+		s.refreshes++
+		s.issue(w)
+	default:
+		cookie, err := r.Cookie("access_token")
+		if err != nil || s.valid == "" || cookie.Value != s.valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code": "unauthorized", "message": "token expired"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": 1}`))
+	}
+}
+
+func TestSessionManagerRefreshesOnUnauthorized(t *testing.T) {
+	backend := &sessionServer{}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	c := NewClient("", server.URL)
+	session := NewSessionManager(c, store)
+
+	resp, err := session.Login(context.Background(), &models.LoginRequest{Email: "admin@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if resp.Data.AccessToken != "access-1" || resp.Data.RefreshToken != "refresh-1" {
+		t.Errorf("Expected tokens from cookies, got %+v", resp.Data)
+	}
+
+	backend.expire()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.GetApps(context.Background(), 1, 20, "", "", nil, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected request to succeed after refresh, got %v", err)
+		}
+	}
+	if backend.refreshes != 1 {
+		t.Errorf("Expected a single refresh for concurrent 401s, got %d", backend.refreshes)
+	}
+
+	saved, _ := store.Load(context.Background())
+	if saved == nil || saved.AccessToken != "access-2" || saved.RefreshToken != "refresh-1" || saved.CSRFToken != "csrf-2" {
+		t.Errorf("Expected refreshed tokens to be saved, got %+v", saved)
+	}
+}
+
+func TestSessionManagerRestore(t *testing.T) {
+	backend := &sessionServer{}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "session.json"))
+	first := NewSessionManager(NewClient("", server.URL), store)
+	if _, err := first.Login(context.Background(), &models.LoginRequest{Email: "admin@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	c := NewClient("", server.URL)
+	restored, err := NewSessionManager(c, store).Restore(context.Background())
+	if err != nil || !restored {
+		t.Fatalf("Expected session to be restored, got %v, %v", restored, err)
+	}
+
+	if _, err := c.GetApps(context.Background(), 1, 20, "", "", nil, nil); err != nil {
+		t.Errorf("Expected restored session to be accepted, got %v", err)
+	}
+	if backend.refreshes != 0 {
+		t.Errorf("Expected no refresh with a valid restored token, got %d", backend.refreshes)
+	}
+}
+
+func TestSessionManagerSharesSessionWithDerivedClient(t *testing.T) {
+	backend := &sessionServer{}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	c := NewClient("", server.URL)
+	session := NewSessionManager(c, nil)
+	if _, err := session.Login(context.Background(), &models.LoginRequest{Email: "admin@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	derived := c.WithWorkspace("ws-1")
+	backend.expire()
+
+	for i := 0; i < 2; i++ {
+		if _, err := derived.GetApps(context.Background(), 1, 20, "", "", nil, nil); err != nil {
+			t.Fatalf("Expected derived client request %d to succeed after refresh, got %v", i+1, err)
+		}
+	}
+	if _, err := c.GetApps(context.Background(), 1, 20, "", "", nil, nil); err != nil {
+		t.Errorf("Expected parent client to use the refreshed session, got %v", err)
+	}
+	if backend.refreshes != 1 {
+		t.Errorf("Expected a single refresh shared by both clients, got %d", backend.refreshes)
+	}
+
+	if err := session.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if _, err := derived.GetApps(context.Background(), 1, 20, "", "", nil, nil); err != nil {
+		t.Errorf("Expected derived client to use explicitly refreshed tokens, got %v", err)
+	}
+	if backend.refreshes != 2 {
+		t.Errorf("Expected no extra refresh after explicit Refresh, got %d", backend.refreshes)
+	}
+}
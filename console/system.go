@@ -2,6 +2,7 @@ package console
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/kingfs/godify/client"
 	"github.com/kingfs/godify/models"
@@ -32,7 +33,7 @@ func (c *Client) GetVersion(ctx context.Context, currentVersion string) (*models
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/version",
-		Query:  map[string]string{"current_version": currentVersion},
+		Query:  url.Values{"current_version": {currentVersion}},
 	}
 
 	var result models.Version
@@ -4,6 +4,7 @@ package console
 import (
 	"context"
 	"errors"
+	"net/url"
 
 	"github.com/kingfs/godify/client"
 	"github.com/kingfs/godify/models"
@@ -129,8 +130,8 @@ func (c *Client) GetModelProviderList(ctx context.Context, model_type string) (*
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/workspaces/current/model-providers",
-		Query: map[string]string{
-			"model_type": model_type,
+		Query: url.Values{
+			"model_type": {model_type},
 		},
 	}
 	var resp models.ModelProvidersResponse
@@ -192,8 +193,8 @@ func (c *Client) GetToolProviderList(ctx context.Context, toolType string) (*mod
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/workspaces/current/tool-providers",
-		Query: map[string]string{
-			"tool_type": toolType, // 可选参数，不传则返回所有工具提供者
+		Query: url.Values{
+			"tool_type": {toolType}, // 可选参数，不传则返回所有工具提供者
 		},
 	}
 	var resp models.ToolProviderListDetailResponse
@@ -312,8 +313,8 @@ func (c *Client) GetToolApiProviderListTools(ctx context.Context, provider strin
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/workspaces/current/tool-provider/api/tools",
-		Query: map[string]string{
-			"provider": provider,
+		Query: url.Values{
+			"provider": {provider},
 		},
 	}
 	var resp models.ApiToolListResponse
@@ -2,6 +2,11 @@ package dataset
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -14,8 +19,8 @@ type Client struct {
 	baseClient *client.BaseClient
 }
 
-// NewClient 创建Dataset API客户端
-func NewClient(datasetToken, baseURL string) *Client {
+// NewClient 创建Dataset API客户端，默认超时30秒、重试3次，可通过opts修改
+func NewClient(datasetToken, baseURL string, opts ...client.Option) *Client {
 	config := &client.ClientConfig{
 		BaseURL:    baseURL + "/v1",
 		AuthType:   client.AuthTypeBearer,
@@ -25,29 +30,43 @@ func NewClient(datasetToken, baseURL string) *Client {
 	}
 
 	return &Client{
-		baseClient: client.NewBaseClient(config),
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
+// WithAPIKey 派生使用另一个API Key的客户端，与原客户端共享连接池与中间件
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	return &Client{baseClient: c.baseClient.Derive(func(config *client.ClientConfig) {
+		config.Token = apiKey
+		config.Authenticator = &client.BearerAuth{Token: apiKey}
+	})}
+}
+
 // ============ 数据集管理 ============
 
 // GetDatasets 获取数据集列表
 func (c *Client) GetDatasets(ctx context.Context, page, limit int, keyword string, tagIDs []string, includeAll bool) (*models.DatasetListForAPIResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if page > 0 {
-		query["page"] = strconv.Itoa(page)
+		query.Set("page", strconv.Itoa(page))
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if keyword != "" {
-		query["keyword"] = keyword
+		query.Set("keyword", keyword)
 	}
 	for _, tagID := range tagIDs {
-		query["tag_ids"] = tagID
+		query.Add("tag_ids", tagID)
 	}
 	if includeAll {
-		query["include_all"] = "true"
+		query.Set("include_all", "true")
 	}
 
 	req := &client.Request{
@@ -61,6 +80,17 @@ func (c *Client) GetDatasets(ctx context.Context, page, limit int, keyword strin
 	return &result, err
 }
 
+// AllDatasets 遍历全部数据集，自动翻页
+func (c *Client) AllDatasets(ctx context.Context, keyword string, tagIDs []string, includeAll bool, opts *client.PagerOptions) iter.Seq2[models.DatasetForAPI, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.DatasetForAPI], error) {
+		resp, err := c.GetDatasets(ctx, page, limit, keyword, tagIDs, includeAll)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.DatasetForAPI]{Items: resp.Data, HasMore: resp.HasMore}, nil
+	}, opts).Items(ctx)
+}
+
 // CreateDataset 创建数据集
 func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetForAPIRequest) (*models.DatasetForAPI, error) {
 	httpReq := &client.Request{
@@ -114,15 +144,15 @@ func (c *Client) DeleteDataset(ctx context.Context, datasetID string) error {
 
 // GetDatasetDocuments 获取数据集文档列表
 func (c *Client) GetDatasetDocuments(ctx context.Context, datasetID string, page, limit int, keyword string) (*models.DocumentListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if page > 0 {
-		query["page"] = strconv.Itoa(page)
+		query.Set("page", strconv.Itoa(page))
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if keyword != "" {
-		query["keyword"] = keyword
+		query.Set("keyword", keyword)
 	}
 
 	req := &client.Request{
@@ -136,86 +166,128 @@ func (c *Client) GetDatasetDocuments(ctx context.Context, datasetID string, page
 	return &result, err
 }
 
-// CreateDocumentByText 通过文本创建文档
-func (c *Client) CreateDocumentByText(ctx context.Context, datasetID string, req *models.CreateDocumentByTextRequest) (*models.DocumentForAPI, error) {
+// AllDocuments 遍历数据集中的全部文档，自动翻页
+func (c *Client) AllDocuments(ctx context.Context, datasetID string, keyword string, opts *client.PagerOptions) iter.Seq2[models.DatasetDocument, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.DatasetDocument], error) {
+		resp, err := c.GetDatasetDocuments(ctx, datasetID, page, limit, keyword)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.DatasetDocument]{Items: resp.Data, HasMore: resp.HasMore}, nil
+	}, opts).Items(ctx)
+}
+
+// CreateDocumentByText 通过文本创建文档，索引异步进行，可将返回的Batch传给WaitForIndexing等待完成
+func (c *Client) CreateDocumentByText(ctx context.Context, datasetID string, req *models.CreateDocumentByTextRequest) (*models.DocumentCreateResponse, error) {
 	httpReq := &client.Request{
 		Method: "POST",
 		Path:   "/datasets/" + datasetID + "/document/create-by-text",
 		Body:   req,
 	}
 
-	var result models.DocumentForAPI
+	var result models.DocumentCreateResponse
 	err := c.baseClient.DoJSON(ctx, httpReq, &result)
 	return &result, err
 }
 
-// CreateDocumentByFile 通过文件创建文档
-func (c *Client) CreateDocumentByFile(ctx context.Context, datasetID string, filename string, fileData []byte, req *models.CreateDocumentByFileRequest) (*models.DocumentForAPI, error) {
-	extraFields := make(map[string]string)
+// CreateDocumentByFile 通过文件创建文档，返回文档及索引批次号
+func (c *Client) CreateDocumentByFile(ctx context.Context, datasetID string, filename string, fileData []byte, req *models.CreateDocumentByFileRequest) (*models.DocumentCreateResponse, error) {
+	var extraFields map[string]string
 	if req != nil {
-		// 将请求参数添加到表单字段中
-		if req.DocForm != "" {
-			extraFields["doc_form"] = req.DocForm
-		}
-		if req.DocLanguage != "" {
-			extraFields["doc_language"] = req.DocLanguage
-		}
-		if req.IndexingTechnique != "" {
-			extraFields["indexing_technique"] = req.IndexingTechnique
+		var err error
+		if extraFields, err = documentFileFields(req); err != nil {
+			return nil, err
 		}
 	}
 
-	_, err := c.baseClient.UploadFile(ctx, "/datasets/"+datasetID+"/document/create-by-file", "file", filename, fileData, extraFields)
+	resp, err := c.baseClient.UploadFile(ctx, "/datasets/"+datasetID+"/document/create-by-file", "file", filename, fileData, extraFields)
 	if err != nil {
 		return nil, err
 	}
+	return decodeDocumentResponse(resp)
+}
 
-	var result models.DocumentForAPI
-	if err := c.baseClient.DoJSON(ctx, &client.Request{}, &result); err != nil {
-		return nil, err
+// CreateDocumentByFileReader 通过文件流创建文档，适用于大文件上传
+func (c *Client) CreateDocumentByFileReader(ctx context.Context, datasetID string, filename string, r io.Reader, req *models.CreateDocumentByFileRequest, opts *client.UploadOptions) (*models.DocumentCreateResponse, error) {
+	var extraFields map[string]string
+	if req != nil {
+		var err error
+		if extraFields, err = documentFileFields(req); err != nil {
+			return nil, err
+		}
 	}
 
-	return &result, nil
+	resp, err := c.baseClient.UploadFileReader(ctx, "/datasets/"+datasetID+"/document/create-by-file", "file", filename, r, opts.WithExtraFields(extraFields))
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocumentResponse(resp)
 }
 
-// UpdateDocumentByText 通过文本更新文档
-func (c *Client) UpdateDocumentByText(ctx context.Context, datasetID, documentID string, req *models.UpdateDocumentByTextRequest) (*models.DocumentForAPI, error) {
+// UpdateDocumentByText 通过文本更新文档，返回文档及重新索引的批次号
+func (c *Client) UpdateDocumentByText(ctx context.Context, datasetID, documentID string, req *models.UpdateDocumentByTextRequest) (*models.DocumentCreateResponse, error) {
 	httpReq := &client.Request{
 		Method: "PUT",
 		Path:   "/datasets/" + datasetID + "/documents/" + documentID + "/update-by-text",
 		Body:   req,
 	}
 
-	var result models.DocumentForAPI
+	var result models.DocumentCreateResponse
 	err := c.baseClient.DoJSON(ctx, httpReq, &result)
 	return &result, err
 }
 
-// UpdateDocumentByFile 通过文件更新文档
-func (c *Client) UpdateDocumentByFile(ctx context.Context, datasetID, documentID string, filename string, fileData []byte, req *models.UpdateDocumentByFileRequest) (*models.DocumentForAPI, error) {
-	extraFields := make(map[string]string)
+// UpdateDocumentByFile 通过文件更新文档，返回文档及重新索引的批次号
+func (c *Client) UpdateDocumentByFile(ctx context.Context, datasetID, documentID string, filename string, fileData []byte, req *models.UpdateDocumentByFileRequest) (*models.DocumentCreateResponse, error) {
+	var extraFields map[string]string
 	if req != nil {
-		if req.DocForm != "" {
-			extraFields["doc_form"] = req.DocForm
-		}
-		if req.DocLanguage != "" {
-			extraFields["doc_language"] = req.DocLanguage
-		}
-		if req.IndexingTechnique != "" {
-			extraFields["indexing_technique"] = req.IndexingTechnique
+		var err error
+		if extraFields, err = documentFileFields(req); err != nil {
+			return nil, err
 		}
 	}
 
-	_, err := c.baseClient.UploadFile(ctx, "/datasets/"+datasetID+"/documents/"+documentID+"/update-by-file", "file", filename, fileData, extraFields)
+	resp, err := c.baseClient.UploadFile(ctx, "/datasets/"+datasetID+"/documents/"+documentID+"/update-by-file", "file", filename, fileData, extraFields)
 	if err != nil {
 		return nil, err
 	}
+	return decodeDocumentResponse(resp)
+}
 
-	var result models.DocumentForAPI
-	if err := c.baseClient.DoJSON(ctx, &client.Request{}, &result); err != nil {
+// UpdateDocumentByFileReader 通过文件流更新文档，适用于大文件上传
+func (c *Client) UpdateDocumentByFileReader(ctx context.Context, datasetID, documentID string, filename string, r io.Reader, req *models.UpdateDocumentByFileRequest, opts *client.UploadOptions) (*models.DocumentCreateResponse, error) {
+	var extraFields map[string]string
+	if req != nil {
+		var err error
+		if extraFields, err = documentFileFields(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.baseClient.UploadFileReader(ctx, "/datasets/"+datasetID+"/documents/"+documentID+"/update-by-file", "file", filename, r, opts.WithExtraFields(extraFields))
+	if err != nil {
 		return nil, err
 	}
+	return decodeDocumentResponse(resp)
+}
+
+// documentFileFields 构建文件上传文档时的表单字段
+//
+// 文档设置（处理规则、索引方式等）以JSON编码后放在data字段中。
+func documentFileFields(req interface{}) (map[string]string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document settings: %w", err)
+	}
+	return map[string]string{"data": string(data)}, nil
+}
 
+// decodeDocumentResponse 解析文件创建/更新文档的响应
+func decodeDocumentResponse(resp *client.Response) (*models.DocumentCreateResponse, error) {
+	var result models.DocumentCreateResponse
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -246,18 +318,18 @@ func (c *Client) GetDocument(ctx context.Context, datasetID, documentID string)
 
 // GetSegments 获取文档分段列表
 func (c *Client) GetSegments(ctx context.Context, datasetID, documentID string, page, limit int, status []string, keyword string) (*models.SegmentListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if page > 0 {
-		query["page"] = strconv.Itoa(page)
+		query.Set("page", strconv.Itoa(page))
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if keyword != "" {
-		query["keyword"] = keyword
+		query.Set("keyword", keyword)
 	}
 	for _, s := range status {
-		query["status"] = s
+		query.Add("status", s)
 	}
 
 	req := &client.Request{
@@ -271,6 +343,17 @@ func (c *Client) GetSegments(ctx context.Context, datasetID, documentID string,
 	return &result, err
 }
 
+// AllSegments 遍历文档的全部分段，自动翻页
+func (c *Client) AllSegments(ctx context.Context, datasetID, documentID string, status []string, keyword string, opts *client.PagerOptions) iter.Seq2[models.DocumentSegment, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.DocumentSegment], error) {
+		resp, err := c.GetSegments(ctx, datasetID, documentID, page, limit, status, keyword)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.DocumentSegment]{Items: resp.Data, HasMore: resp.HasMore}, nil
+	}, opts).Items(ctx)
+}
+
 // CreateSegments 创建分段
 func (c *Client) CreateSegments(ctx context.Context, datasetID, documentID string, req *models.CreateSegmentsRequest) (*models.SegmentListResponse, error) {
 	httpReq := &client.Request{
@@ -287,14 +370,16 @@ func (c *Client) CreateSegments(ctx context.Context, datasetID, documentID strin
 // UpdateSegment 更新分段
 func (c *Client) UpdateSegment(ctx context.Context, datasetID, documentID, segmentID string, req *models.UpdateSegmentRequest) (*models.SegmentForAPI, error) {
 	httpReq := &client.Request{
-		Method: "PUT",
+		Method: "POST",
 		Path:   "/datasets/" + datasetID + "/documents/" + documentID + "/segments/" + segmentID,
-		Body:   req,
+		Body:   map[string]interface{}{"segment": req},
 	}
 
-	var result models.SegmentForAPI
-	err := c.baseClient.DoJSON(ctx, httpReq, &result)
-	return &result, err
+	var result models.SegmentResponse
+	if err := c.baseClient.DoJSON(ctx, httpReq, &result); err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
 }
 
 // DeleteSegment 删除分段
@@ -308,6 +393,89 @@ func (c *Client) DeleteSegment(ctx context.Context, datasetID, documentID, segme
 	return c.baseClient.DoJSON(ctx, req, &result)
 }
 
+// ============ 子分段管理 ============
+
+// childChunksPath 分段下子分段的路径
+func childChunksPath(datasetID, documentID, segmentID string) string {
+	return "/datasets/" + datasetID + "/documents/" + documentID + "/segments/" + segmentID + "/child_chunks"
+}
+
+// GetChildChunks 获取分段的子分段列表
+func (c *Client) GetChildChunks(ctx context.Context, datasetID, documentID, segmentID string, page, limit int, keyword string) (*models.ChildChunkListResponse, error) {
+	query := url.Values{}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if keyword != "" {
+		query.Set("keyword", keyword)
+	}
+
+	req := &client.Request{
+		Method: "GET",
+		Path:   childChunksPath(datasetID, documentID, segmentID),
+		Query:  query,
+	}
+
+	var result models.ChildChunkListResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// AllChildChunks 遍历分段的全部子分段，自动翻页
+func (c *Client) AllChildChunks(ctx context.Context, datasetID, documentID, segmentID string, keyword string, opts *client.PagerOptions) iter.Seq2[models.ChildChunk, error] {
+	return client.NewPager(func(ctx context.Context, page int, _ string, limit int) (*client.Page[models.ChildChunk], error) {
+		resp, err := c.GetChildChunks(ctx, datasetID, documentID, segmentID, page, limit, keyword)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.ChildChunk]{Items: resp.Data, HasMore: page < resp.TotalPages}, nil
+	}, opts).Items(ctx)
+}
+
+// CreateChildChunk 在分段下创建子分段
+func (c *Client) CreateChildChunk(ctx context.Context, datasetID, documentID, segmentID string, req *models.ChildChunkRequest) (*models.ChildChunk, error) {
+	httpReq := &client.Request{
+		Method: "POST",
+		Path:   childChunksPath(datasetID, documentID, segmentID),
+		Body:   req,
+	}
+
+	var result models.ChildChunkResponse
+	if err := c.baseClient.DoJSON(ctx, httpReq, &result); err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
+// UpdateChildChunk 更新子分段
+func (c *Client) UpdateChildChunk(ctx context.Context, datasetID, documentID, segmentID, childChunkID string, req *models.ChildChunkRequest) (*models.ChildChunk, error) {
+	httpReq := &client.Request{
+		Method: "PATCH",
+		Path:   childChunksPath(datasetID, documentID, segmentID) + "/" + childChunkID,
+		Body:   req,
+	}
+
+	var result models.ChildChunkResponse
+	if err := c.baseClient.DoJSON(ctx, httpReq, &result); err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
+// DeleteChildChunk 删除子分段
+func (c *Client) DeleteChildChunk(ctx context.Context, datasetID, documentID, segmentID, childChunkID string) error {
+	req := &client.Request{
+		Method: "DELETE",
+		Path:   childChunksPath(datasetID, documentID, segmentID) + "/" + childChunkID,
+	}
+
+	var result map[string]string
+	return c.baseClient.DoJSON(ctx, req, &result)
+}
+
 // ============ 命中测试 ============
 
 // HitTestDataset 数据集命中测试
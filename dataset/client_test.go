@@ -0,0 +1,270 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+func TestDatasetAuthHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/datasets/ds-1" {
+			t.Errorf("Expected path /v1/datasets/ds-1, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer dataset-token" {
+			t.Errorf("Expected Authorization 'Bearer dataset-token', got %s", auth)
+		}
+		if cookie := r.Header.Get("Cookie"); cookie != "" {
+			t.Errorf("Expected no cookies, got %s", cookie)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "ds-1", "name": "Docs"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("dataset-token", server.URL)
+	dataset, err := client.GetDataset(context.Background(), "ds-1")
+	if err != nil {
+		t.Fatalf("GetDataset failed: %v", err)
+	}
+
+	if dataset.ID != "ds-1" {
+		t.Errorf("Expected dataset ID 'ds-1', got %s", dataset.ID)
+	}
+}
+
+func TestCreateDocumentByFileSendsProcessRule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse multipart form: %v", err)
+			return
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(r.FormValue("data")), &data); err != nil {
+			t.Errorf("Expected JSON data field, got %q", r.FormValue("data"))
+			return
+		}
+		if data["indexing_technique"] != "high_quality" || data["doc_form"] != "hierarchical_model" {
+			t.Errorf("Unexpected document settings: %v", data)
+		}
+
+		rule := data["process_rule"].(map[string]interface{})
+		rules := rule["rules"].(map[string]interface{})
+		if rule["mode"] != "hierarchical" || rules["parent_mode"] != "paragraph" {
+			t.Errorf("Unexpected process rule: %v", rule)
+		}
+		child := rules["subchunk_segmentation"].(map[string]interface{})
+		if child["max_tokens"] != float64(200) || child["separator"] != "\n" {
+			t.Errorf("Unexpected subchunk segmentation: %v", child)
+		}
+		preProcessing := rules["pre_processing_rules"].([]interface{})
+		if len(preProcessing) != 2 || preProcessing[1].(map[string]interface{})["enabled"] != true {
+			t.Errorf("Expected remove_urls_emails to be enabled, got %v", preProcessing)
+		}
+		if data["embedding_model"] != "text-embedding-3-small" {
+			t.Errorf("Expected embedding model, got %v", data["embedding_model"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"document": {"id": "doc-1", "name": "guide.md"}, "batch": "batch-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("dataset-token", server.URL)
+	req := &models.CreateDocumentByFileRequest{
+		IndexingTechnique: models.IndexingTechniqueHighQuality,
+		DocForm:           models.DocFormHierarchical,
+		ProcessRule: models.NewHierarchicalProcessRule(models.ParentModeParagraph,
+			models.Segmentation{Separator: "\n\n", MaxTokens: 1000},
+			models.Segmentation{Separator: "\n", MaxTokens: 200},
+			models.PreProcessingRemoveURLsEmails),
+		RetrievalModel:         &models.RetrievalModel{SearchMethod: models.SearchMethodHybrid, TopK: 5},
+		EmbeddingModel:         "text-embedding-3-small",
+		EmbeddingModelProvider: "openai",
+	}
+	resp, err := client.CreateDocumentByFile(context.Background(), "ds-1", "guide.md", []byte("# Guide"), req)
+	if err != nil {
+		t.Fatalf("CreateDocumentByFile failed: %v", err)
+	}
+
+	if resp.Document.ID != "doc-1" || resp.Batch != "batch-1" {
+		t.Errorf("Expected document 'doc-1' in batch 'batch-1', got %s in %s", resp.Document.ID, resp.Batch)
+	}
+}
+
+func TestRepeatedQueryFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/datasets":
+			if tags := query["tag_ids"]; len(tags) != 2 || tags[0] != "tag-1" || tags[1] != "tag-2" {
+				t.Errorf("Expected tag_ids [tag-1 tag-2], got %v", tags)
+			}
+			w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": 1}`))
+		case "/v1/datasets/ds-1/documents/doc-1/segments":
+			if status := query["status"]; len(status) != 2 || status[0] != "completed" || status[1] != "error" {
+				t.Errorf("Expected status [completed error], got %v", status)
+			}
+			w.Write([]byte(`{"data": [], "has_more": false, "limit": 20, "total": 0, "page": 1}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("dataset-token", server.URL)
+	if _, err := client.GetDatasets(context.Background(), 1, 20, "", []string{"tag-1", "tag-2"}, false); err != nil {
+		t.Fatalf("GetDatasets failed: %v", err)
+	}
+	if _, err := client.GetSegments(context.Background(), "ds-1", "doc-1", 1, 20, []string{"completed", "error"}, ""); err != nil {
+		t.Fatalf("GetSegments failed: %v", err)
+	}
+}
+
+func TestAllDocuments(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if limit := r.URL.Query().Get("limit"); limit != "2" {
+			t.Errorf("Expected limit 2, got %s", limit)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if page == "1" {
+			w.Write([]byte(`{"data": [{"id": "doc-1"}, {"id": "doc-2"}], "has_more": true, "limit": 2, "total": 3, "page": 1}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "doc-3"}], "has_more": false, "limit": 2, "total": 3, "page": 2}`))
+	}))
+	defer server.Close()
+
+	opts := &client.PagerOptions{PageSize: 2}
+	client := NewClient("dataset-token", server.URL)
+	var ids []string
+	for doc, err := range client.AllDocuments(context.Background(), "ds-1", "", opts) {
+		if err != nil {
+			t.Fatalf("AllDocuments failed: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	if len(ids) != 3 || ids[2] != "doc-3" {
+		t.Errorf("Expected documents [doc-1 doc-2 doc-3], got %v", ids)
+	}
+	if len(pages) != 2 {
+		t.Errorf("Expected 2 page requests, got %v", pages)
+	}
+}
+
+func TestChildChunks(t *testing.T) {
+	const base = "/v1/datasets/ds-1/documents/doc-1/segments/seg-1/child_chunks"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET " + base:
+			if keyword := r.URL.Query().Get("keyword"); keyword != "refund" {
+				t.Errorf("Expected keyword 'refund', got %s", keyword)
+			}
+			w.Write([]byte(`{"data": [{"id": "chunk-1", "segment_id": "seg-1", "content": "Refunds take 7 days", "position": 1, "type": "automatic"}], "total": 1, "total_pages": 1, "page": 1, "limit": 20}`))
+		case "POST " + base, "PATCH " + base + "/chunk-2":
+			var req models.ChildChunkRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content == "" {
+				t.Errorf("Expected child chunk content, got %+v (%v)", req, err)
+			}
+			w.Write([]byte(`{"data": {"id": "chunk-2", "segment_id": "seg-1", "content": "` + req.Content + `", "type": "customized"}}`))
+		case "DELETE " + base + "/chunk-2":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("dataset-token", server.URL)
+	ctx := context.Background()
+
+	list, err := client.GetChildChunks(ctx, "ds-1", "doc-1", "seg-1", 1, 20, "refund")
+	if err != nil {
+		t.Fatalf("GetChildChunks failed: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].SegmentID != "seg-1" || list.TotalPages != 1 {
+		t.Errorf("Unexpected child chunk list: %+v", list)
+	}
+
+	created, err := client.CreateChildChunk(ctx, "ds-1", "doc-1", "seg-1", &models.ChildChunkRequest{Content: "Contact support"})
+	if err != nil {
+		t.Fatalf("CreateChildChunk failed: %v", err)
+	}
+	if created.ID != "chunk-2" || created.Content != "Contact support" {
+		t.Errorf("Unexpected created child chunk: %+v", created)
+	}
+
+	updated, err := client.UpdateChildChunk(ctx, "ds-1", "doc-1", "seg-1", "chunk-2", &models.ChildChunkRequest{Content: "Email support"})
+	if err != nil {
+		t.Fatalf("UpdateChildChunk failed: %v", err)
+	}
+	if updated.Content != "Email support" {
+		t.Errorf("Expected updated content 'Email support', got %s", updated.Content)
+	}
+
+	if err := client.DeleteChildChunk(ctx, "ds-1", "doc-1", "seg-1", "chunk-2"); err != nil {
+		t.Errorf("DeleteChildChunk failed: %v", err)
+	}
+}
+
+func TestSegmentChildChunksDecode(t *testing.T) {
+	var segment models.SegmentForAPI
+	data := `{"id": "seg-1", "keywords": ["refund", "policy"], "enabled": true, "child_chunks": [{"id": "chunk-1", "position": 1}, {"id": "chunk-2", "position": 2}]}`
+	if err := json.Unmarshal([]byte(data), &segment); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !segment.Enabled || len(segment.Keywords) != 2 || len(segment.ChildChunks) != 2 || segment.ChildChunks[1].ID != "chunk-2" {
+		t.Errorf("Unexpected segment: %+v", segment)
+	}
+}
+
+func TestUpdateSegment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/datasets/ds-1/documents/doc-1/segments/seg-1" {
+			t.Errorf("Expected POST /v1/datasets/ds-1/documents/doc-1/segments/seg-1, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		segment := body["segment"]
+		if segment["content"] != "Refunds take 7 days" || segment["enabled"] != false || segment["regenerate_child_chunks"] != true {
+			t.Errorf("Unexpected segment body: %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "seg-1", "content": "Refunds take 7 days", "enabled": false}, "doc_form": "hierarchical_model"}`))
+	}))
+	defer server.Close()
+
+	enabled := false
+	client := NewClient("dataset-token", server.URL)
+	segment, err := client.UpdateSegment(context.Background(), "ds-1", "doc-1", "seg-1", &models.UpdateSegmentRequest{
+		Content:               "Refunds take 7 days",
+		Enabled:               &enabled,
+		RegenerateChildChunks: true,
+	})
+	if err != nil {
+		t.Fatalf("UpdateSegment failed: %v", err)
+	}
+
+	if segment.ID != "seg-1" {
+		t.Errorf("Expected segment ID 'seg-1', got %s", segment.ID)
+	}
+}
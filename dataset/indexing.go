@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+// GetIndexingStatus 获取批次中各文档的索引进度
+func (c *Client) GetIndexingStatus(ctx context.Context, datasetID, batch string) (*models.IndexingStatusResponse, error) {
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/datasets/" + datasetID + "/documents/" + batch + "/indexing-status",
+	}
+
+	var result models.IndexingStatusResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// WaitOptions WaitForIndexing的轮询选项
+type WaitOptions struct {
+	// Interval 首次轮询间隔，默认1秒，之后每次翻倍
+	Interval time.Duration
+	// MaxInterval 轮询间隔上限，默认10秒
+	MaxInterval time.Duration
+	// OnProgress 每次获取到索引进度后调用
+	OnProgress func(statuses []models.DocumentIndexingStatus)
+}
+
+// IndexingError 文档索引失败或被暂停
+type IndexingError struct {
+	DocumentID string
+	// Status 索引状态，为models.IndexingStatusError或models.IndexingStatusPaused
+	Status  string
+	Message string
+}
+
+func (e *IndexingError) Error() string {
+	if e.Status == models.IndexingStatusPaused {
+		return fmt.Sprintf("indexing paused for document %s", e.DocumentID)
+	}
+	return fmt.Sprintf("indexing failed for document %s: %s", e.DocumentID, e.Message)
+}
+
+// Paused 索引是否被暂停
+func (e *IndexingError) Paused() bool {
+	return e.Status == models.IndexingStatusPaused
+}
+
+// WaitForIndexing 轮询批次的索引进度直到全部文档完成
+//
+// 任一文档出错或被暂停时返回 *IndexingError，ctx结束时返回ctx的错误；
+// 返回的进度为最后一次查询的结果。opts可为nil。
+// 批次尚不可见或不存在时返回的进度为空，会持续轮询，调用方应通过ctx设置截止时间。
+func (c *Client) WaitForIndexing(ctx context.Context, datasetID, batch string, opts *WaitOptions) ([]models.DocumentIndexingStatus, error) {
+	interval, maxInterval := time.Second, 10*time.Second
+	var onProgress func([]models.DocumentIndexingStatus)
+	if opts != nil {
+		if opts.Interval > 0 {
+			interval = opts.Interval
+		}
+		if opts.MaxInterval > 0 {
+			maxInterval = opts.MaxInterval
+		}
+		onProgress = opts.OnProgress
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	for {
+		resp, err := c.GetIndexingStatus(ctx, datasetID, batch)
+		if err != nil {
+			return nil, err
+		}
+		if onProgress != nil {
+			onProgress(resp.Data)
+		}
+
+		// 空批次视为尚未开始，而不是已完成
+		done := len(resp.Data) > 0
+		for _, status := range resp.Data {
+			switch status.IndexingStatus {
+			case models.IndexingStatusError, models.IndexingStatusPaused:
+				return resp.Data, &IndexingError{DocumentID: status.ID, Status: status.IndexingStatus, Message: status.Error}
+			case models.IndexingStatusCompleted:
+			default:
+				done = false
+			}
+		}
+		if done {
+			return resp.Data, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp.Data, ctx.Err()
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
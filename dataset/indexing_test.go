@@ -0,0 +1,116 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kingfs/godify/models"
+)
+
+// indexingServer 依次返回预设的索引状态
+func indexingServer(t *testing.T, statuses ...string) *httptest.Server {
+	calls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/datasets/ds-1/documents/batch-1/indexing-status" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		status := statuses[min(calls, len(statuses)-1)]
+		calls++
+		message := ""
+		if status == "error" {
+			message = "embedding quota exceeded"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": [{"id": "doc-1", "indexing_status": %q, "processing_started_at": 1700000000, "completed_at": null, "error": %q, "completed_segments": %d, "total_segments": 4}]}`,
+			status, message, calls)
+	}))
+}
+
+func TestWaitForIndexingReportsProgress(t *testing.T) {
+	server := indexingServer(t, "parsing", "indexing", "completed")
+	defer server.Close()
+
+	var progress []float64
+	client := NewClient("dataset-token", server.URL)
+	statuses, err := client.WaitForIndexing(context.Background(), "ds-1", "batch-1", &WaitOptions{
+		Interval: time.Millisecond,
+		OnProgress: func(statuses []models.DocumentIndexingStatus) {
+			progress = append(progress, statuses[0].Progress())
+		},
+	})
+	if err != nil {
+		t.Fatalf("WaitForIndexing failed: %v", err)
+	}
+
+	if len(progress) != 3 || progress[0] != 0.25 || progress[2] != 1 {
+		t.Errorf("Expected progress [0.25 0.5 1], got %v", progress)
+	}
+	if statuses[0].ProcessingStartedAt == nil || statuses[0].CompletedAt != nil {
+		t.Errorf("Unexpected stage timestamps: %+v", statuses[0])
+	}
+}
+
+func TestWaitForIndexingReturnsIndexingError(t *testing.T) {
+	for _, status := range []string{"error", "paused"} {
+		t.Run(status, func(t *testing.T) {
+			server := indexingServer(t, "splitting", status)
+			defer server.Close()
+
+			client := NewClient("dataset-token", server.URL)
+			_, err := client.WaitForIndexing(context.Background(), "ds-1", "batch-1", &WaitOptions{Interval: time.Millisecond})
+
+			var indexingErr *IndexingError
+			if !errors.As(err, &indexingErr) {
+				t.Fatalf("Expected *IndexingError, got %v", err)
+			}
+			if indexingErr.DocumentID != "doc-1" || indexingErr.Paused() != (status == "paused") {
+				t.Errorf("Unexpected indexing error: %+v", indexingErr)
+			}
+			if status == "error" && indexingErr.Message != "embedding quota exceeded" {
+				t.Errorf("Expected error message from server, got %q", indexingErr.Message)
+			}
+		})
+	}
+}
+
+func TestWaitForIndexingHonoursContext(t *testing.T) {
+	server := indexingServer(t, "waiting")
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClient("dataset-token", server.URL)
+	_, err := client.WaitForIndexing(ctx, "ds-1", "batch-1", &WaitOptions{Interval: 10 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWaitForIndexingKeepsPollingEmptyBatch(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClient("dataset-token", server.URL)
+	_, err := client.WaitForIndexing(ctx, "ds-1", "batch-unknown", &WaitOptions{Interval: 5 * time.Millisecond, MaxInterval: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded for empty batch, got %v", err)
+	}
+	if calls < 2 {
+		t.Errorf("Expected empty batch to be polled repeatedly, got %d calls", calls)
+	}
+}
@@ -2,6 +2,7 @@
 package godify
 
 import (
+	"github.com/kingfs/godify/client"
 	"github.com/kingfs/godify/console"
 	"github.com/kingfs/godify/dataset"
 	"github.com/kingfs/godify/files"
@@ -13,45 +14,46 @@ import (
 // NewServiceClient 创建 Service API 客户端
 // appToken: 应用 API Token
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewServiceClient(appToken, baseURL string) *service.Client {
-	return service.NewClient(appToken, baseURL)
+// opts: 可选配置，如 client.WithTimeout、client.WithProxy
+func NewServiceClient(appToken, baseURL string, opts ...client.Option) *service.Client {
+	return service.NewClient(appToken, baseURL, opts...)
 }
 
 // NewWebClient 创建 Web API 客户端
 // appCode: 应用代码，可以从Dify控制台获取
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewWebClient(baseURL string) *web.Client {
-	return web.NewClient(baseURL)
+func NewWebClient(baseURL string, opts ...client.Option) *web.Client {
+	return web.NewClient(baseURL, opts...)
 }
 
 // NewConsoleClient 创建 Console API 客户端 (管理员API)
 // accessToken: 访问令牌或会话令牌
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewConsoleClient(accessToken, baseURL string) *console.Client {
-	return console.NewClient(accessToken, baseURL)
+func NewConsoleClient(accessToken, baseURL string, opts ...client.Option) *console.Client {
+	return console.NewClient(accessToken, baseURL, opts...)
 }
 
 // NewConsoleClientWithSession 使用Session Cookie创建 Console API 客户端
 // sessionCookie: 会话Cookie
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewConsoleClientWithSession(sessionCookie, baseURL string) *console.Client {
-	return console.NewClientWithSession(sessionCookie, baseURL)
+func NewConsoleClientWithSession(sessionCookie, baseURL string, opts ...client.Option) *console.Client {
+	return console.NewClientWithSession(sessionCookie, baseURL, opts...)
 }
 
 // NewFilesClient 创建 Files API 客户端
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewFilesClient(baseURL string) *files.Client {
-	return files.NewClient(baseURL)
+func NewFilesClient(baseURL string, opts ...client.Option) *files.Client {
+	return files.NewClient(baseURL, opts...)
 }
 
 // NewMCPClient 创建 MCP API 客户端 (Model Context Protocol)
 // baseURL: Dify 服务器地址，例如 "https://api.dify.ai"
-func NewMCPClient(baseURL string) *mcp.Client {
-	return mcp.NewClient(baseURL)
+func NewMCPClient(baseURL string, opts ...client.Option) *mcp.Client {
+	return mcp.NewClient(baseURL, opts...)
 }
 
 // NewDatasetClient 创建Dataset API客户端 (面向数据集管理)
 // datasetToken: 数据集API Token，可以从Dify控制台获取
-func NewDatasetClient(datasetToken, baseURL string) *dataset.Client {
-	return dataset.NewClient(datasetToken, baseURL)
+func NewDatasetClient(datasetToken, baseURL string, opts ...client.Option) *dataset.Client {
+	return dataset.NewClient(datasetToken, baseURL, opts...)
 }
@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+)
 
 // ErrorResponse API错误响应结构
 type ErrorResponse struct {
@@ -15,6 +19,8 @@ type APIError struct {
 	Code       string      `json:"code"`
 	Message    string      `json:"message"`
 	Details    interface{} `json:"details,omitempty"`
+	// RequestID 请求的X-Request-Id，便于与服务端日志关联
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func (e *APIError) Error() string {
@@ -24,6 +30,19 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API error [%d]: %s", e.StatusCode, e.Message)
 }
 
+// Is 按错误码匹配预定义错误，目标没有错误码时按状态码匹配，
+// 使 errors.Is(err, ErrInvokeRateLimit) 等判断在错误被包装后仍然有效
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Code != "" {
+		return e.Code == t.Code
+	}
+	return t.StatusCode != 0 && e.StatusCode == t.StatusCode
+}
+
 // 预定义的错误类型
 var (
 	ErrAppUnavailable                           = &APIError{Code: "app_unavailable", Message: "App unavailable"}
@@ -46,18 +65,124 @@ var (
 	ErrProviderNotSupportSpeechToText           = &APIError{Code: "provider_not_support_speech_to_text", Message: "Provider not support speech to text"}
 	ErrAppMoreLikeThisDisabled                  = &APIError{Code: "app_more_like_this_disabled", Message: "App more like this disabled"}
 	ErrAppSuggestedQuestionsAfterAnswerDisabled = &APIError{Code: "app_suggested_questions_after_answer_disabled", Message: "App suggested questions after answer disabled"}
+	ErrNotWorkflowApp                           = &APIError{Code: "not_workflow_app", Message: "App is not a workflow app"}
+	ErrNoAudioUploaded                          = &APIError{Code: "no_audio_uploaded", Message: "No audio uploaded"}
+	ErrFilenameNotExists                        = &APIError{Code: "filename_not_exists_error", Message: "Filename not exists"}
+)
+
+// 知识库相关错误
+var (
+	ErrDatasetNotInitialized     = &APIError{Code: "dataset_not_initialized", Message: "Dataset not initialized"}
+	ErrDatasetNameDuplicate      = &APIError{Code: "dataset_name_duplicate", Message: "Dataset name duplicate"}
+	ErrDatasetInUse              = &APIError{Code: "dataset_in_use", Message: "Dataset in use"}
+	ErrHighQualityDatasetOnly    = &APIError{Code: "high_quality_dataset_only", Message: "High quality dataset only"}
+	ErrDocumentIndexing          = &APIError{Code: "document_indexing", Message: "Document indexing"}
+	ErrDocumentAlreadyFinished   = &APIError{Code: "document_already_finished", Message: "Document already finished"}
+	ErrArchivedDocumentImmutable = &APIError{Code: "archived_document_immutable", Message: "Archived document immutable"}
+	ErrInvalidAction             = &APIError{Code: "invalid_action", Message: "Invalid action"}
+	ErrInvalidMetadata           = &APIError{Code: "invalid_metadata", Message: "Invalid metadata"}
+)
+
+// 工作流相关错误
+var (
+	ErrDraftWorkflowNotExist = &APIError{Code: "draft_workflow_not_exist", Message: "Draft workflow not exist"}
+	ErrDraftWorkflowNotSync  = &APIError{Code: "draft_workflow_not_sync", Message: "Draft workflow not sync"}
+)
+
+// 认证与通用HTTP错误
+var (
+	ErrUnauthorized            = &APIError{Code: "unauthorized", Message: "Unauthorized"}
+	ErrForbidden               = &APIError{Code: "forbidden", Message: "Forbidden"}
+	ErrAccountBanned           = &APIError{Code: "account_banned", Message: "Account banned"}
+	ErrEmailOrPasswordMismatch = &APIError{Code: "email_or_password_mismatch", Message: "Email or password mismatch"}
+	ErrNotSetup                = &APIError{Code: "not_setup", Message: "Not setup"}
+	ErrNotFound                = &APIError{Code: "not_found", Message: "Not found"}
+	ErrInvalidParam            = &APIError{Code: "invalid_param", Message: "Invalid param"}
+	ErrTooManyRequests         = &APIError{Code: "too_many_requests", Message: "Too many requests"}
 )
 
-// IsAPIError 检查是否为API错误
+// IsAPIError 检查是否为API错误，支持被包装的错误
 func IsAPIError(err error) bool {
-	_, ok := err.(*APIError)
-	return ok
+	return GetAPIError(err) != nil
 }
 
-// GetAPIError 获取API错误详情
+// GetAPIError 获取API错误详情，支持被包装的错误
 func GetAPIError(err error) *APIError {
-	if apiErr, ok := err.(*APIError); ok {
+	var apiErr *APIError
+	if stderrors.As(err, &apiErr) {
 		return apiErr
 	}
 	return nil
 }
+
+// IsRetryable 检查错误是否为可稍后重试的临时错误（限流、超时、网络错误或服务端暂时不可用）
+func IsRetryable(err error) bool {
+	var netErr *NetworkError
+	var timeoutErr *TimeoutError
+	if stderrors.As(err, &netErr) || stderrors.As(err, &timeoutErr) {
+		return true
+	}
+
+	apiErr := GetAPIError(err)
+	if apiErr == nil {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsRateLimited 检查错误是否为限流
+func IsRateLimited(err error) bool {
+	apiErr := GetAPIError(err)
+	if apiErr == nil {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests ||
+		stderrors.Is(err, ErrInvokeRateLimit) || stderrors.Is(err, ErrTooManyRequests)
+}
+
+// IsAuthError 检查错误是否为认证或授权失败
+func IsAuthError(err error) bool {
+	apiErr := GetAPIError(err)
+	if apiErr == nil {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+		return true
+	}
+	for _, target := range []error{ErrUnauthorized, ErrForbidden, ErrAccountBanned, ErrEmailOrPasswordMismatch} {
+		if stderrors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsQuotaError 检查错误是否为模型供应商额度耗尽
+func IsQuotaError(err error) bool {
+	return stderrors.Is(err, ErrProviderQuotaExceeded)
+}
+
+// IsNotFound 检查错误是否为资源不存在
+func IsNotFound(err error) bool {
+	apiErr := GetAPIError(err)
+	if apiErr == nil {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	for _, target := range []error{ErrNotFound, ErrConversationNotExists, ErrMessageNotExists, ErrDraftWorkflowNotExist} {
+		if stderrors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
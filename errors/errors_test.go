@@ -0,0 +1,69 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorIsMatchesCodeThroughWrapping(t *testing.T) {
+	err := fmt.Errorf("chat failed: %w", &APIError{StatusCode: 400, Code: "conversation_not_exists", Message: "Conversation Not Exists."})
+
+	if !stderrors.Is(err, ErrConversationNotExists) {
+		t.Error("Expected wrapped error to match ErrConversationNotExists")
+	}
+	if stderrors.Is(err, ErrMessageNotExists) {
+		t.Error("Expected wrapped error not to match ErrMessageNotExists")
+	}
+
+	if !IsAPIError(err) {
+		t.Error("Expected IsAPIError to unwrap the error")
+	}
+	if apiErr := GetAPIError(err); apiErr == nil || apiErr.StatusCode != 400 {
+		t.Errorf("Expected GetAPIError to return the wrapped APIError, got %v", apiErr)
+	}
+
+	if !stderrors.Is(err, &APIError{StatusCode: http.StatusBadRequest}) {
+		t.Error("Expected status-only target to match by status code")
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		auth      bool
+		quota     bool
+		notFound  bool
+	}{
+		{"rate limit code", &APIError{StatusCode: 400, Code: "invoke_rate_limit"}, true, false, false, false},
+		{"429", &APIError{StatusCode: 429, Code: "too_many_requests"}, true, false, false, false},
+		{"503", &APIError{StatusCode: 503}, true, false, false, false},
+		{"401", &APIError{StatusCode: 401, Code: "unauthorized"}, false, true, false, false},
+		{"banned", &APIError{StatusCode: 400, Code: "account_banned"}, false, true, false, false},
+		{"quota", fmt.Errorf("wrapped: %w", &APIError{StatusCode: 400, Code: "provider_quota_exceeded"}), false, false, true, false},
+		{"404", &APIError{StatusCode: 404, Code: "not_found"}, false, false, false, true},
+		{"network", fmt.Errorf("wrapped: %w", &NetworkError{RequestInfo: RequestInfo{Method: "GET", Path: "/apps"}, Err: stderrors.New("connection reset")}), true, false, false, false},
+		{"timeout", &TimeoutError{RequestInfo: RequestInfo{Method: "POST", Path: "/chat-messages"}, Err: stderrors.New("deadline exceeded")}, true, false, false, false},
+		{"plain error", stderrors.New("boom"), false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable: expected %v, got %v", tt.retryable, got)
+			}
+			if got := IsAuthError(tt.err); got != tt.auth {
+				t.Errorf("IsAuthError: expected %v, got %v", tt.auth, got)
+			}
+			if got := IsQuotaError(tt.err); got != tt.quota {
+				t.Errorf("IsQuotaError: expected %v, got %v", tt.quota, got)
+			}
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound: expected %v, got %v", tt.notFound, got)
+			}
+		})
+	}
+}
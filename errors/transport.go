@@ -0,0 +1,99 @@
+package errors
+
+import "fmt"
+
+// RequestInfo 出错请求的上下文信息
+type RequestInfo struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Attempts  int    `json:"attempts"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// String 返回请求描述
+func (r RequestInfo) String() string {
+	s := fmt.Sprintf("%s %s", r.Method, r.Path)
+	if r.Attempts > 1 {
+		s += fmt.Sprintf(" after %d attempts", r.Attempts)
+	}
+	if r.RequestID != "" {
+		s += " (request_id=" + r.RequestID + ")"
+	}
+	return s
+}
+
+// NetworkError 请求未得到HTTP响应，如连接失败、连接被重置
+type NetworkError struct {
+	RequestInfo
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error: %s: %v", e.RequestInfo, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// TimeoutError 请求超时，包括客户端超时与ctx截止时间
+type TimeoutError struct {
+	RequestInfo
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timeout: %s: %v", e.RequestInfo, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Timeout 实现net.Error风格的超时判断
+func (e *TimeoutError) Timeout() bool { return true }
+
+// DecodeError 响应体无法解析，Body保存响应体开头的一段内容
+type DecodeError struct {
+	RequestInfo
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// maxDecodeErrorBody DecodeError中保留的响应体最大长度
+const maxDecodeErrorBody = 512
+
+// NewDecodeError 创建解析错误，响应体超过上限时截断
+func NewDecodeError(info RequestInfo, statusCode int, body []byte, err error) *DecodeError {
+	snippet := body
+	if len(snippet) > maxDecodeErrorBody {
+		snippet = snippet[:maxDecodeErrorBody]
+	}
+	return &DecodeError{
+		RequestInfo: info,
+		StatusCode:  statusCode,
+		Body:        string(snippet),
+		Err:         err,
+	}
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response [%d]: %s: %v", e.StatusCode, e.RequestInfo, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// StreamError 流式响应在读取过程中中断
+type StreamError struct {
+	RequestInfo
+	// LastEvent 最后一个成功处理的事件类型
+	LastEvent string
+	// LastEventID 最后一个事件ID
+	LastEventID string
+	// EventCount 中断前已处理的事件数量
+	EventCount int
+	Err        error
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("stream interrupted after %d events (last event %q, id %q): %s: %v", e.EventCount, e.LastEvent, e.LastEventID, e.RequestInfo, e.Err)
+}
+
+func (e *StreamError) Unwrap() error { return e.Err }
@@ -2,6 +2,7 @@ package files
 
 import (
 	"context"
+	"net/url"
 	"time"
 
 	"github.com/kingfs/godify/client"
@@ -13,8 +14,8 @@ type Client struct {
 	baseClient *client.BaseClient
 }
 
-// NewClient 创建Files API客户端
-func NewClient(baseURL string) *Client {
+// NewClient 创建Files API客户端，默认超时30秒、重试3次，可通过opts修改
+func NewClient(baseURL string, opts ...client.Option) *Client {
 	config := &client.ClientConfig{
 		BaseURL:    baseURL + "/files",
 		Timeout:    30 * time.Second,
@@ -22,10 +23,16 @@ func NewClient(baseURL string) *Client {
 	}
 
 	return &Client{
-		baseClient: client.NewBaseClient(config),
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
 // PluginUploadRequest 插件文件上传请求参数
 type PluginUploadRequest struct {
 	TenantID  string
@@ -37,12 +44,12 @@ type PluginUploadRequest struct {
 
 // UploadForPlugin 为插件上传文件
 func (c *Client) UploadForPlugin(ctx context.Context, filename string, fileData []byte, mimetype string, req *PluginUploadRequest) (*models.FileUpload, error) {
-	query := map[string]string{
-		"tenant_id": req.TenantID,
-		"user_id":   req.UserID,
-		"timestamp": req.Timestamp,
-		"nonce":     req.Nonce,
-		"sign":      req.Sign,
+	query := url.Values{
+		"tenant_id": {req.TenantID},
+		"user_id":   {req.UserID},
+		"timestamp": {req.Timestamp},
+		"nonce":     {req.Nonce},
+		"sign":      {req.Sign},
 	}
 
 	// 设置查询参数
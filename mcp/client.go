@@ -12,8 +12,8 @@ type Client struct {
 	baseClient *client.BaseClient
 }
 
-// NewClient 创建MCP API客户端
-func NewClient(baseURL string) *Client {
+// NewClient 创建MCP API客户端，默认超时30秒、重试3次，可通过opts修改
+func NewClient(baseURL string, opts ...client.Option) *Client {
 	config := &client.ClientConfig{
 		BaseURL:    baseURL + "/mcp",
 		Timeout:    30 * time.Second,
@@ -21,10 +21,16 @@ func NewClient(baseURL string) *Client {
 	}
 
 	return &Client{
-		baseClient: client.NewBaseClient(config),
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
 // MCPRequest MCP请求
 type MCPRequest struct {
 	Method string                 `json:"method"`
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -72,6 +73,61 @@ type FileUpload struct {
 	URL       string   `json:"source_url,omitempty"`
 }
 
+// FileType 文件类型，对应应用输入中files的type字段
+type FileType string
+
+const (
+	FileTypeDocument FileType = "document"
+	FileTypeImage    FileType = "image"
+	FileTypeAudio    FileType = "audio"
+	FileTypeVideo    FileType = "video"
+	FileTypeCustom   FileType = "custom"
+)
+
+// documentExtensions Dify支持的文档扩展名
+var documentExtensions = map[string]struct{}{
+	"txt": {}, "md": {}, "markdown": {}, "pdf": {}, "html": {}, "xlsx": {}, "xls": {},
+	"docx": {}, "csv": {}, "eml": {}, "msg": {}, "pptx": {}, "ppt": {}, "xml": {}, "epub": {},
+}
+
+// FileType 根据MIME类型和扩展名推断文件类型
+func (f *FileUpload) FileType() FileType {
+	mimeType := strings.ToLower(f.MimeType)
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return FileTypeImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		return FileTypeAudio
+	case strings.HasPrefix(mimeType, "video/"):
+		return FileTypeVideo
+	}
+
+	if _, ok := documentExtensions[strings.ToLower(strings.TrimPrefix(f.Extension, "."))]; ok {
+		return FileTypeDocument
+	}
+	return FileTypeCustom
+}
+
+// InputFile 转换为ChatRequest.Files等请求中使用的本地文件条目
+func (f *FileUpload) InputFile() map[string]interface{} {
+	return map[string]interface{}{
+		"type":            string(f.FileType()),
+		"transfer_method": "local_file",
+		"upload_file_id":  f.ID,
+	}
+}
+
+// AudioToTextResponse 语音转文字响应
+type AudioToTextResponse struct {
+	Text string `json:"text"`
+}
+
+// TextToAudioResponse 文字转语音响应
+type TextToAudioResponse struct {
+	ContentType string
+	Audio       []byte
+}
+
 // AppMode 应用模式
 type AppMode string
 
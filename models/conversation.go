@@ -16,6 +16,14 @@ type ConversationListResponse struct {
 	Data []Conversation `json:"data"`
 }
 
+// LastID 本页最后一个对话的ID，用作下一页的last_id
+func (r *ConversationListResponse) LastID() string {
+	if len(r.Data) == 0 {
+		return ""
+	}
+	return r.Data[len(r.Data)-1].ID
+}
+
 // ConversationRenameRequest 对话重命名请求
 type ConversationRenameRequest struct {
 	Name         *string `json:"name,omitempty"`
@@ -32,3 +40,20 @@ type SimpleConversation struct {
 type ConversationOperationResponse struct {
 	Result string `json:"result"`
 }
+
+// ConversationVariable 对话变量
+type ConversationVariable struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	ValueType   string      `json:"value_type"`
+	Value       interface{} `json:"value"`
+	Description string      `json:"description"`
+	CreatedAt   UnixTime    `json:"created_at"`
+	UpdatedAt   UnixTime    `json:"updated_at"`
+}
+
+// ConversationVariableListResponse 对话变量列表响应
+type ConversationVariableListResponse struct {
+	InfiniteScrollPagination
+	Data []ConversationVariable `json:"data"`
+}
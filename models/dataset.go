@@ -28,6 +28,150 @@ type DocumentForAPI struct {
 	IsDeleted             bool      `json:"is_deleted"`
 }
 
+// 文档索引方式
+const (
+	IndexingTechniqueHighQuality = "high_quality"
+	IndexingTechniqueEconomy     = "economy"
+)
+
+// 文档分段形式
+const (
+	DocFormText         = "text_model"
+	DocFormHierarchical = "hierarchical_model"
+	DocFormQA           = "qa_model"
+)
+
+// ProcessRuleMode 文档处理规则模式
+type ProcessRuleMode string
+
+const (
+	// ProcessRuleModeAutomatic 使用服务端默认的分段与清洗规则
+	ProcessRuleModeAutomatic ProcessRuleMode = "automatic"
+	// ProcessRuleModeCustom 自定义分段与清洗规则
+	ProcessRuleModeCustom ProcessRuleMode = "custom"
+	// ProcessRuleModeHierarchical 父子分段，需配合DocFormHierarchical使用
+	ProcessRuleModeHierarchical ProcessRuleMode = "hierarchical"
+)
+
+// PreProcessingRuleID 预处理规则标识
+type PreProcessingRuleID string
+
+const (
+	// PreProcessingRemoveExtraSpaces 替换连续的空格、换行和制表符
+	PreProcessingRemoveExtraSpaces PreProcessingRuleID = "remove_extra_spaces"
+	// PreProcessingRemoveURLsEmails 删除所有URL和电子邮件地址
+	PreProcessingRemoveURLsEmails PreProcessingRuleID = "remove_urls_emails"
+)
+
+// ParentMode 父子分段中父分段的召回模式
+type ParentMode string
+
+const (
+	// ParentModeFullDoc 整篇文档作为父分段
+	ParentModeFullDoc ParentMode = "full-doc"
+	// ParentModeParagraph 按段落切分父分段
+	ParentModeParagraph ParentMode = "paragraph"
+)
+
+// ProcessRule 文档处理规则，自定义与父子分段模式需要设置Rules
+type ProcessRule struct {
+	Mode  ProcessRuleMode   `json:"mode"`
+	Rules *ProcessRuleRules `json:"rules,omitempty"`
+}
+
+// ProcessRuleRules 自定义处理规则
+type ProcessRuleRules struct {
+	PreProcessingRules []PreProcessingRule `json:"pre_processing_rules"`
+	Segmentation       *Segmentation       `json:"segmentation,omitempty"`
+	// ParentMode 父子分段模式下父分段的召回模式
+	ParentMode ParentMode `json:"parent_mode,omitempty"`
+	// SubchunkSegmentation 父子分段模式下子分段的切分规则
+	SubchunkSegmentation *Segmentation `json:"subchunk_segmentation,omitempty"`
+}
+
+// PreProcessingRule 预处理规则
+type PreProcessingRule struct {
+	ID      PreProcessingRuleID `json:"id"`
+	Enabled bool                `json:"enabled"`
+}
+
+// Segmentation 分段规则
+type Segmentation struct {
+	// Separator 分段标识符，如"\n\n"
+	Separator string `json:"separator,omitempty"`
+	// MaxTokens 单个分段的最大token数
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// ChunkOverlap 相邻分段重叠的token数
+	ChunkOverlap int `json:"chunk_overlap,omitempty"`
+}
+
+// NewAutomaticProcessRule 创建自动处理规则
+func NewAutomaticProcessRule() *ProcessRule {
+	return &ProcessRule{Mode: ProcessRuleModeAutomatic}
+}
+
+// NewCustomProcessRule 创建自定义分段规则，preProcessing为需要启用的预处理规则
+func NewCustomProcessRule(segmentation Segmentation, preProcessing ...PreProcessingRuleID) *ProcessRule {
+	return &ProcessRule{
+		Mode: ProcessRuleModeCustom,
+		Rules: &ProcessRuleRules{
+			PreProcessingRules: preProcessingRules(preProcessing),
+			Segmentation:       &segmentation,
+		},
+	}
+}
+
+// NewHierarchicalProcessRule 创建父子分段规则，parentMode为ParentModeFullDoc时忽略parent
+func NewHierarchicalProcessRule(parentMode ParentMode, parent, child Segmentation, preProcessing ...PreProcessingRuleID) *ProcessRule {
+	return &ProcessRule{
+		Mode: ProcessRuleModeHierarchical,
+		Rules: &ProcessRuleRules{
+			PreProcessingRules:   preProcessingRules(preProcessing),
+			Segmentation:         &parent,
+			ParentMode:           parentMode,
+			SubchunkSegmentation: &child,
+		},
+	}
+}
+
+// preProcessingRules 列出全部预处理规则，enabled中的规则启用，其余显式关闭
+func preProcessingRules(enabled []PreProcessingRuleID) []PreProcessingRule {
+	rules := []PreProcessingRule{
+		{ID: PreProcessingRemoveExtraSpaces},
+		{ID: PreProcessingRemoveURLsEmails},
+	}
+	for i := range rules {
+		for _, id := range enabled {
+			if rules[i].ID == id {
+				rules[i].Enabled = true
+			}
+		}
+	}
+	return rules
+}
+
+// SearchMethod 检索方式
+type SearchMethod string
+
+const (
+	SearchMethodSemantic SearchMethod = "semantic_search"
+	SearchMethodFullText SearchMethod = "full_text_search"
+	SearchMethodHybrid   SearchMethod = "hybrid_search"
+	SearchMethodKeyword  SearchMethod = "keyword_search"
+)
+
+// RetrievalModel 检索设置
+type RetrievalModel struct {
+	SearchMethod          SearchMethod    `json:"search_method"`
+	RerankingEnable       bool            `json:"reranking_enable"`
+	RerankingMode         string          `json:"reranking_mode,omitempty"`
+	RerankingModel        *RerankingModel `json:"reranking_model,omitempty"`
+	Weights               *WeightsConfig  `json:"weights,omitempty"`
+	TopK                  int             `json:"top_k"`
+	ScoreThresholdEnabled bool            `json:"score_threshold_enabled"`
+	ScoreThreshold        float64         `json:"score_threshold,omitempty"`
+}
+
 // CreateDocumentByTextRequest 通过文本创建文档请求
 type CreateDocumentByTextRequest struct {
 	Name              string `json:"name"`
@@ -36,6 +180,12 @@ type CreateDocumentByTextRequest struct {
 	DocForm           string `json:"doc_form,omitempty"`
 	DocLanguage       string `json:"doc_language,omitempty"`
 	IndexingTechnique string `json:"indexing_technique,omitempty"`
+	// ProcessRule 处理规则，为nil时使用服务端默认规则
+	ProcessRule *ProcessRule `json:"process_rule,omitempty"`
+	// RetrievalModel 检索设置，仅在知识库首次创建文档时生效
+	RetrievalModel         *RetrievalModel `json:"retrieval_model,omitempty"`
+	EmbeddingModel         string          `json:"embedding_model,omitempty"`
+	EmbeddingModelProvider string          `json:"embedding_model_provider,omitempty"`
 }
 
 // CreateDocumentByFileRequest 通过文件创建文档请求
@@ -45,25 +195,94 @@ type CreateDocumentByFileRequest struct {
 	DocForm           string `json:"doc_form,omitempty"`
 	DocLanguage       string `json:"doc_language,omitempty"`
 	IndexingTechnique string `json:"indexing_technique,omitempty"`
+	// ProcessRule 处理规则，为nil时使用服务端默认规则
+	ProcessRule *ProcessRule `json:"process_rule,omitempty"`
+	// RetrievalModel 检索设置，仅在知识库首次创建文档时生效
+	RetrievalModel         *RetrievalModel `json:"retrieval_model,omitempty"`
+	EmbeddingModel         string          `json:"embedding_model,omitempty"`
+	EmbeddingModelProvider string          `json:"embedding_model_provider,omitempty"`
 }
 
 // UpdateDocumentByTextRequest 通过文本更新文档请求
 type UpdateDocumentByTextRequest struct {
-	Name              string `json:"name,omitempty"`
-	Description       string `json:"description,omitempty"`
-	Content           string `json:"content"`
-	DocForm           string `json:"doc_form,omitempty"`
-	DocLanguage       string `json:"doc_language,omitempty"`
-	IndexingTechnique string `json:"indexing_technique,omitempty"`
+	Name              string       `json:"name,omitempty"`
+	Description       string       `json:"description,omitempty"`
+	Content           string       `json:"content"`
+	DocForm           string       `json:"doc_form,omitempty"`
+	DocLanguage       string       `json:"doc_language,omitempty"`
+	IndexingTechnique string       `json:"indexing_technique,omitempty"`
+	ProcessRule       *ProcessRule `json:"process_rule,omitempty"`
 }
 
 // UpdateDocumentByFileRequest 通过文件更新文档请求
 type UpdateDocumentByFileRequest struct {
-	Name              string `json:"name,omitempty"`
-	Description       string `json:"description,omitempty"`
-	DocForm           string `json:"doc_form,omitempty"`
-	DocLanguage       string `json:"doc_language,omitempty"`
-	IndexingTechnique string `json:"indexing_technique,omitempty"`
+	Name              string       `json:"name,omitempty"`
+	Description       string       `json:"description,omitempty"`
+	DocForm           string       `json:"doc_form,omitempty"`
+	DocLanguage       string       `json:"doc_language,omitempty"`
+	IndexingTechnique string       `json:"indexing_technique,omitempty"`
+	ProcessRule       *ProcessRule `json:"process_rule,omitempty"`
+}
+
+// DocumentCreateResponse 创建或更新文档的响应，Batch用于查询索引进度
+type DocumentCreateResponse struct {
+	Document DocumentForAPI `json:"document"`
+	Batch    string         `json:"batch"`
+}
+
+// 文档索引状态
+const (
+	IndexingStatusWaiting   = "waiting"
+	IndexingStatusParsing   = "parsing"
+	IndexingStatusCleaning  = "cleaning"
+	IndexingStatusSplitting = "splitting"
+	IndexingStatusIndexing  = "indexing"
+	IndexingStatusCompleted = "completed"
+	IndexingStatusError     = "error"
+	IndexingStatusPaused    = "paused"
+)
+
+// DocumentIndexingStatus 文档索引进度
+//
+// 各阶段完成时间为nil表示该阶段尚未完成。
+type DocumentIndexingStatus struct {
+	ID                   string    `json:"id"`
+	IndexingStatus       string    `json:"indexing_status"`
+	ProcessingStartedAt  *UnixTime `json:"processing_started_at"`
+	ParsingCompletedAt   *UnixTime `json:"parsing_completed_at"`
+	CleaningCompletedAt  *UnixTime `json:"cleaning_completed_at"`
+	SplittingCompletedAt *UnixTime `json:"splitting_completed_at"`
+	CompletedAt          *UnixTime `json:"completed_at"`
+	PausedAt             *UnixTime `json:"paused_at"`
+	StoppedAt            *UnixTime `json:"stopped_at"`
+	Error                string    `json:"error"`
+	CompletedSegments    int       `json:"completed_segments"`
+	TotalSegments        int       `json:"total_segments"`
+}
+
+// Finished 索引是否已结束（完成、出错或暂停）
+func (s *DocumentIndexingStatus) Finished() bool {
+	switch s.IndexingStatus {
+	case IndexingStatusCompleted, IndexingStatusError, IndexingStatusPaused:
+		return true
+	}
+	return false
+}
+
+// Progress 已完成分段的比例（0-1），分段尚未切分完成时为0
+func (s *DocumentIndexingStatus) Progress() float64 {
+	if s.IndexingStatus == IndexingStatusCompleted {
+		return 1
+	}
+	if s.TotalSegments == 0 {
+		return 0
+	}
+	return float64(s.CompletedSegments) / float64(s.TotalSegments)
+}
+
+// IndexingStatusResponse 批次索引进度响应
+type IndexingStatusResponse struct {
+	Data []DocumentIndexingStatus `json:"data"`
 }
 
 // SegmentForAPI API分段
@@ -80,11 +299,16 @@ type SegmentForAPI struct {
 	Position       int       `json:"position"`
 	IsEmpty        bool      `json:"is_empty"`
 	HitCount       int       `json:"hit_count"`
+	Keywords       []string  `json:"keywords"`
+	Enabled        bool      `json:"enabled"`
+	DisabledAt     *UnixTime `json:"disabled_at,omitempty"`
 	IndexingStatus string    `json:"indexing_status"`
 	CompletedAt    *UnixTime `json:"completed_at,omitempty"`
 	ErrorMessage   string    `json:"error_message,omitempty"`
 	CreatedAt      UnixTime  `json:"created_at"`
 	UpdatedAt      UnixTime  `json:"updated_at"`
+	// ChildChunks 父子分段模式下的子分段
+	ChildChunks []ChildChunk `json:"child_chunks,omitempty"`
 }
 
 // CreateSegmentsRequest 创建分段请求
@@ -94,14 +318,56 @@ type CreateSegmentsRequest struct {
 
 // SegmentData 分段数据
 type SegmentData struct {
-	Content string `json:"content"`
-	Answer  string `json:"answer,omitempty"`
+	Content  string   `json:"content"`
+	Answer   string   `json:"answer,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
 }
 
 // UpdateSegmentRequest 更新分段请求
 type UpdateSegmentRequest struct {
+	Content  string   `json:"content"`
+	Answer   string   `json:"answer,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Enabled  *bool    `json:"enabled,omitempty"`
+	// RegenerateChildChunks 父子分段模式下按新内容重新生成子分段
+	RegenerateChildChunks bool `json:"regenerate_child_chunks,omitempty"`
+}
+
+// SegmentResponse 分段响应
+type SegmentResponse struct {
+	Data    SegmentForAPI `json:"data"`
+	DocForm string        `json:"doc_form"`
+}
+
+// ChildChunk 父子分段模式下的子分段
+type ChildChunk struct {
+	ID        string   `json:"id"`
+	SegmentID string   `json:"segment_id"`
+	Content   string   `json:"content"`
+	Position  int      `json:"position"`
+	WordCount int      `json:"word_count"`
+	Type      string   `json:"type"`
+	CreatedAt UnixTime `json:"created_at"`
+	UpdatedAt UnixTime `json:"updated_at"`
+}
+
+// ChildChunkRequest 创建或更新子分段请求
+type ChildChunkRequest struct {
 	Content string `json:"content"`
-	Answer  string `json:"answer,omitempty"`
+}
+
+// ChildChunkResponse 子分段响应
+type ChildChunkResponse struct {
+	Data ChildChunk `json:"data"`
+}
+
+// ChildChunkListResponse 子分段列表响应
+type ChildChunkListResponse struct {
+	Data       []ChildChunk `json:"data"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"total_pages"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
 }
 
 // MetadataForAPI API元数据
@@ -65,6 +65,14 @@ type MessageListResponse struct {
 	Data []Message `json:"data"`
 }
 
+// FirstID 本页最早一条消息的ID，用作获取更早消息的first_id
+func (r *MessageListResponse) FirstID() string {
+	if len(r.Data) == 0 {
+		return ""
+	}
+	return r.Data[0].ID
+}
+
 // MessageFeedbackRequest 消息反馈请求
 type MessageFeedbackRequest struct {
 	Rating  *string `json:"rating,omitempty"` // "like", "dislike"
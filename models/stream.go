@@ -0,0 +1,281 @@
+package models
+
+import "encoding/json"
+
+// StreamEventType 流式事件类型
+type StreamEventType string
+
+const (
+	StreamEventMessage          StreamEventType = "message"
+	StreamEventAgentMessage     StreamEventType = "agent_message"
+	StreamEventAgentThought     StreamEventType = "agent_thought"
+	StreamEventMessageFile      StreamEventType = "message_file"
+	StreamEventMessageEnd       StreamEventType = "message_end"
+	StreamEventMessageReplace   StreamEventType = "message_replace"
+	StreamEventTTSMessage       StreamEventType = "tts_message"
+	StreamEventTTSMessageEnd    StreamEventType = "tts_message_end"
+	StreamEventWorkflowStarted  StreamEventType = "workflow_started"
+	StreamEventNodeStarted      StreamEventType = "node_started"
+	StreamEventNodeFinished     StreamEventType = "node_finished"
+	StreamEventWorkflowFinished StreamEventType = "workflow_finished"
+	StreamEventTextChunk        StreamEventType = "text_chunk"
+	StreamEventIterationStarted StreamEventType = "iteration_started"
+	StreamEventIterationNext    StreamEventType = "iteration_next"
+	StreamEventIterationDone    StreamEventType = "iteration_completed"
+	StreamEventLoopStarted      StreamEventType = "loop_started"
+	StreamEventLoopNext         StreamEventType = "loop_next"
+	StreamEventLoopDone         StreamEventType = "loop_completed"
+	StreamEventError            StreamEventType = "error"
+	StreamEventPing             StreamEventType = "ping"
+)
+
+// StreamEvent 流式事件
+type StreamEvent interface {
+	EventType() StreamEventType
+}
+
+// StreamEventBase 流式事件公共字段
+type StreamEventBase struct {
+	Event          StreamEventType `json:"event"`
+	TaskID         string          `json:"task_id,omitempty"`
+	MessageID      string          `json:"message_id,omitempty"`
+	ConversationID string          `json:"conversation_id,omitempty"`
+	CreatedAt      int64           `json:"created_at,omitempty"`
+}
+
+// EventType 返回事件类型
+func (e *StreamEventBase) EventType() StreamEventType {
+	return e.Event
+}
+
+// MessageEvent LLM返回文本块事件 (message)
+type MessageEvent struct {
+	StreamEventBase
+	ID     string `json:"id,omitempty"`
+	Answer string `json:"answer"`
+}
+
+// AgentMessageEvent Agent模式下返回文本块事件 (agent_message)
+type AgentMessageEvent struct {
+	MessageEvent
+}
+
+// AgentThoughtEvent Agent思考步骤事件 (agent_thought)
+type AgentThoughtEvent struct {
+	StreamEventBase
+	ID           string                 `json:"id"`
+	Position     int                    `json:"position"`
+	Thought      string                 `json:"thought"`
+	Observation  string                 `json:"observation"`
+	Tool         string                 `json:"tool"`
+	ToolLabels   map[string]interface{} `json:"tool_labels,omitempty"`
+	ToolInput    string                 `json:"tool_input"`
+	MessageFiles []string               `json:"message_files"`
+}
+
+// MessageFileEvent 文件事件 (message_file)
+type MessageFileEvent struct {
+	StreamEventBase
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	BelongsTo string `json:"belongs_to"`
+	URL       string `json:"url"`
+}
+
+// Usage 模型用量
+type Usage struct {
+	PromptTokens        int         `json:"prompt_tokens"`
+	PromptUnitPrice     json.Number `json:"prompt_unit_price,omitempty"`
+	PromptPriceUnit     json.Number `json:"prompt_price_unit,omitempty"`
+	PromptPrice         json.Number `json:"prompt_price,omitempty"`
+	CompletionTokens    int         `json:"completion_tokens"`
+	CompletionUnitPrice json.Number `json:"completion_unit_price,omitempty"`
+	CompletionPriceUnit json.Number `json:"completion_price_unit,omitempty"`
+	CompletionPrice     json.Number `json:"completion_price,omitempty"`
+	TotalTokens         int         `json:"total_tokens"`
+	TotalPrice          json.Number `json:"total_price,omitempty"`
+	Currency            string      `json:"currency,omitempty"`
+	Latency             float64     `json:"latency,omitempty"`
+}
+
+// MessageEndMetadata 消息结束事件元数据
+type MessageEndMetadata struct {
+	Usage              *Usage              `json:"usage,omitempty"`
+	RetrieverResources []RetrieverResource `json:"retriever_resources,omitempty"`
+}
+
+// MessageEndEvent 消息结束事件 (message_end)
+type MessageEndEvent struct {
+	StreamEventBase
+	ID       string             `json:"id,omitempty"`
+	Metadata MessageEndMetadata `json:"metadata"`
+}
+
+// MessageReplaceEvent 消息内容替换事件 (message_replace)，通常由内容审查触发
+type MessageReplaceEvent struct {
+	StreamEventBase
+	Answer string `json:"answer"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// TTSMessageEvent 语音合成音频块事件 (tts_message / tts_message_end)
+type TTSMessageEvent struct {
+	StreamEventBase
+	// Audio base64编码的音频数据
+	Audio string `json:"audio"`
+}
+
+// WorkflowEventBase 工作流事件公共字段
+type WorkflowEventBase struct {
+	StreamEventBase
+	WorkflowRunID string `json:"workflow_run_id"`
+}
+
+// WorkflowStartedData 工作流开始数据
+type WorkflowStartedData struct {
+	ID             string                 `json:"id"`
+	WorkflowID     string                 `json:"workflow_id"`
+	SequenceNumber int                    `json:"sequence_number"`
+	Inputs         map[string]interface{} `json:"inputs,omitempty"`
+	CreatedAt      int64                  `json:"created_at"`
+}
+
+// WorkflowStartedEvent 工作流开始事件 (workflow_started)
+type WorkflowStartedEvent struct {
+	WorkflowEventBase
+	Data WorkflowStartedData `json:"data"`
+}
+
+// NodeStartedData 节点开始数据
+type NodeStartedData struct {
+	ID                string                 `json:"id"`
+	NodeID            string                 `json:"node_id"`
+	NodeType          string                 `json:"node_type"`
+	Title             string                 `json:"title"`
+	Index             int                    `json:"index"`
+	PredecessorNodeID string                 `json:"predecessor_node_id,omitempty"`
+	Inputs            map[string]interface{} `json:"inputs,omitempty"`
+	CreatedAt         int64                  `json:"created_at"`
+}
+
+// NodeStartedEvent 节点开始事件 (node_started)
+type NodeStartedEvent struct {
+	WorkflowEventBase
+	Data NodeStartedData `json:"data"`
+}
+
+// NodeExecutionMetadata 节点执行元数据
+type NodeExecutionMetadata struct {
+	TotalTokens int         `json:"total_tokens,omitempty"`
+	TotalPrice  json.Number `json:"total_price,omitempty"`
+	Currency    string      `json:"currency,omitempty"`
+}
+
+// NodeFinishedData 节点结束数据
+type NodeFinishedData struct {
+	ID                string                 `json:"id"`
+	NodeID            string                 `json:"node_id"`
+	NodeType          string                 `json:"node_type"`
+	Title             string                 `json:"title"`
+	Index             int                    `json:"index"`
+	PredecessorNodeID string                 `json:"predecessor_node_id,omitempty"`
+	Inputs            map[string]interface{} `json:"inputs,omitempty"`
+	ProcessData       map[string]interface{} `json:"process_data,omitempty"`
+	Outputs           map[string]interface{} `json:"outputs,omitempty"`
+	Status            string                 `json:"status"`
+	Error             string                 `json:"error,omitempty"`
+	ElapsedTime       float64                `json:"elapsed_time"`
+	ExecutionMetadata *NodeExecutionMetadata `json:"execution_metadata,omitempty"`
+	CreatedAt         int64                  `json:"created_at"`
+	FinishedAt        int64                  `json:"finished_at,omitempty"`
+}
+
+// NodeFinishedEvent 节点结束事件 (node_finished)
+type NodeFinishedEvent struct {
+	WorkflowEventBase
+	Data NodeFinishedData `json:"data"`
+}
+
+// WorkflowFinishedData 工作流结束数据
+type WorkflowFinishedData struct {
+	ID          string                 `json:"id"`
+	WorkflowID  string                 `json:"workflow_id"`
+	Status      string                 `json:"status"`
+	Outputs     map[string]interface{} `json:"outputs,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	ElapsedTime float64                `json:"elapsed_time"`
+	TotalTokens int                    `json:"total_tokens"`
+	TotalSteps  int                    `json:"total_steps"`
+	CreatedAt   int64                  `json:"created_at"`
+	FinishedAt  int64                  `json:"finished_at"`
+}
+
+// WorkflowFinishedEvent 工作流结束事件 (workflow_finished)
+type WorkflowFinishedEvent struct {
+	WorkflowEventBase
+	Data WorkflowFinishedData `json:"data"`
+}
+
+// TextChunkData 工作流文本块数据
+type TextChunkData struct {
+	Text                 string   `json:"text"`
+	FromVariableSelector []string `json:"from_variable_selector,omitempty"`
+}
+
+// TextChunkEvent 工作流文本块事件 (text_chunk)
+type TextChunkEvent struct {
+	WorkflowEventBase
+	Data TextChunkData `json:"data"`
+}
+
+// ContainerNodeData 迭代/循环节点数据
+//
+// started、next、completed三个阶段共用该结构，各阶段只填充部分字段。
+type ContainerNodeData struct {
+	ID                string                 `json:"id"`
+	NodeID            string                 `json:"node_id"`
+	NodeType          string                 `json:"node_type"`
+	Title             string                 `json:"title"`
+	Index             int                    `json:"index,omitempty"`
+	Inputs            map[string]interface{} `json:"inputs,omitempty"`
+	Outputs           map[string]interface{} `json:"outputs,omitempty"`
+	Status            string                 `json:"status,omitempty"`
+	Error             string                 `json:"error,omitempty"`
+	ElapsedTime       float64                `json:"elapsed_time,omitempty"`
+	TotalTokens       int                    `json:"total_tokens,omitempty"`
+	Steps             int                    `json:"steps,omitempty"`
+	ExecutionMetadata *NodeExecutionMetadata `json:"execution_metadata,omitempty"`
+	CreatedAt         int64                  `json:"created_at"`
+	FinishedAt        int64                  `json:"finished_at,omitempty"`
+}
+
+// IterationEvent 迭代节点事件 (iteration_started / iteration_next / iteration_completed)
+type IterationEvent struct {
+	WorkflowEventBase
+	Data ContainerNodeData `json:"data"`
+}
+
+// LoopEvent 循环节点事件 (loop_started / loop_next / loop_completed)
+type LoopEvent struct {
+	WorkflowEventBase
+	Data ContainerNodeData `json:"data"`
+}
+
+// ErrorEvent 流式输出过程中的异常事件 (error)
+type ErrorEvent struct {
+	StreamEventBase
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PingEvent 保活事件 (ping)
+type PingEvent struct {
+	StreamEventBase
+}
+
+// UnknownEvent 未识别的事件，保留原始数据
+type UnknownEvent struct {
+	StreamEventBase
+	Raw json.RawMessage `json:"-"`
+}
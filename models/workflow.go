@@ -17,8 +17,10 @@ type Workflow struct {
 
 // WorkflowRunRequest 工作流运行请求
 type WorkflowRunRequest struct {
-	Inputs map[string]interface{}   `json:"inputs"`
-	Files  []map[string]interface{} `json:"files,omitempty"`
+	Inputs       map[string]interface{}   `json:"inputs"`
+	Files        []map[string]interface{} `json:"files,omitempty"`
+	ResponseMode ResponseMode             `json:"response_mode,omitempty"`
+	User         string                   `json:"user,omitempty"`
 }
 
 // WorkflowRunResponse 工作流运行响应
@@ -79,6 +81,34 @@ type WorkflowRunListResponse struct {
 	Page    int           `json:"page"`
 }
 
+// WorkflowAppLogEndUser 工作流日志中的终端用户
+type WorkflowAppLogEndUser struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	SessionID   string `json:"session_id"`
+}
+
+// WorkflowAppLog 工作流应用日志
+type WorkflowAppLog struct {
+	ID               string                 `json:"id"`
+	WorkflowRun      *WorkflowRun           `json:"workflow_run"`
+	CreatedFrom      string                 `json:"created_from"`
+	CreatedByRole    string                 `json:"created_by_role"`
+	CreatedByAccount map[string]interface{} `json:"created_by_account,omitempty"`
+	CreatedByEndUser *WorkflowAppLogEndUser `json:"created_by_end_user,omitempty"`
+	CreatedAt        UnixTime               `json:"created_at"`
+}
+
+// WorkflowAppLogListResponse 工作流应用日志列表响应
+type WorkflowAppLogListResponse struct {
+	Data    []WorkflowAppLog `json:"data"`
+	HasMore bool             `json:"has_more"`
+	Limit   int              `json:"limit"`
+	Total   int              `json:"total"`
+	Page    int              `json:"page"`
+}
+
 // DraftWorkflowRequest 草稿工作流请求
 type DraftWorkflowRequest struct {
 	Graph    map[string]interface{} `json:"graph"`
@@ -192,6 +222,8 @@ type DocumentSegment struct {
 	CompletedAt *UnixTime `json:"completed_at"`
 	Error       string    `json:"error,omitempty"`
 	StoppedAt   *UnixTime `json:"stopped_at"`
+	// ChildChunks 父子分段模式下的子分段
+	ChildChunks []ChildChunk `json:"child_chunks,omitempty"`
 }
 
 // SegmentListResponse 片段列表响应
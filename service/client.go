@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"iter"
 	"time"
 
 	"github.com/kingfs/godify/client"
@@ -13,8 +14,8 @@ type Client struct {
 	baseClient *client.BaseClient
 }
 
-// NewClient 创建Service API客户端
-func NewClient(appToken, baseURL string) *Client {
+// NewClient 创建Service API客户端，默认超时30秒、重试3次，可通过opts修改
+func NewClient(appToken, baseURL string, opts ...client.Option) *Client {
 	config := &client.ClientConfig{
 		BaseURL:    baseURL + "/v1",
 		AuthType:   client.AuthTypeBearer,
@@ -24,10 +25,24 @@ func NewClient(appToken, baseURL string) *Client {
 	}
 
 	return &Client{
-		baseClient: client.NewBaseClient(config),
+		baseClient: client.NewBaseClient(config, opts...),
 	}
 }
 
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
+// WithAPIKey 派生使用另一个API Key的客户端，与原客户端共享连接池与中间件
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	return &Client{baseClient: c.baseClient.Derive(func(config *client.ClientConfig) {
+		config.Token = apiKey
+		config.Authenticator = &client.BearerAuth{Token: apiKey}
+	})}
+}
+
 // GetAppParameters 获取应用参数
 func (c *Client) GetAppParameters(ctx context.Context) (*models.AppParameters, error) {
 	req := &client.Request{
@@ -151,7 +166,23 @@ func (c *Client) ChatStop(ctx context.Context, taskID string, user string) error
 }
 
 // ChatStream 流式聊天对话
+// handler 可使用 *client.TypedSSEHandler 按事件类型接收解析后的事件
 func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, handler client.SSEHandler) error {
+	return c.baseClient.StreamResponse(ctx, chatStreamRequest(req), handler)
+}
+
+// ChatStreamChan 以通道形式返回流式聊天事件，收到message_end或错误后通道关闭
+func (c *Client) ChatStreamChan(ctx context.Context, req *ChatRequest) <-chan client.StreamResult {
+	return c.baseClient.StreamChan(ctx, chatStreamRequest(req))
+}
+
+// ChatStreamIter 以迭代器形式返回流式聊天事件
+func (c *Client) ChatStreamIter(ctx context.Context, req *ChatRequest) iter.Seq2[models.StreamEvent, error] {
+	return c.baseClient.StreamEvents(ctx, chatStreamRequest(req))
+}
+
+// chatStreamRequest 构建流式聊天请求
+func chatStreamRequest(req *ChatRequest) *client.Request {
 	// 强制设置为流式模式
 	req.ResponseMode = models.ResponseModeStreaming
 
@@ -159,17 +190,31 @@ func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, handler clien
 		req.RetrieverFrom = "dev"
 	}
 
-	httpReq := &client.Request{
+	return &client.Request{
 		Method: "POST",
 		Path:   "/chat-messages",
 		Body:   req,
 	}
-
-	return c.baseClient.StreamResponse(ctx, httpReq, handler)
 }
 
 // CompletionStream 流式文本补全
+// handler 可使用 *client.TypedSSEHandler 按事件类型接收解析后的事件
 func (c *Client) CompletionStream(ctx context.Context, req *CompletionRequest, handler client.SSEHandler) error {
+	return c.baseClient.StreamResponse(ctx, completionStreamRequest(req), handler)
+}
+
+// CompletionStreamChan 以通道形式返回流式补全事件，收到message_end或错误后通道关闭
+func (c *Client) CompletionStreamChan(ctx context.Context, req *CompletionRequest) <-chan client.StreamResult {
+	return c.baseClient.StreamChan(ctx, completionStreamRequest(req))
+}
+
+// CompletionStreamIter 以迭代器形式返回流式补全事件
+func (c *Client) CompletionStreamIter(ctx context.Context, req *CompletionRequest) iter.Seq2[models.StreamEvent, error] {
+	return c.baseClient.StreamEvents(ctx, completionStreamRequest(req))
+}
+
+// completionStreamRequest 构建流式补全请求
+func completionStreamRequest(req *CompletionRequest) *client.Request {
 	// 强制设置为流式模式
 	req.ResponseMode = models.ResponseModeStreaming
 
@@ -177,11 +222,9 @@ func (c *Client) CompletionStream(ctx context.Context, req *CompletionRequest, h
 		req.RetrieverFrom = "dev"
 	}
 
-	httpReq := &client.Request{
+	return &client.Request{
 		Method: "POST",
 		Path:   "/completion-messages",
 		Body:   req,
 	}
-
-	return c.baseClient.StreamResponse(ctx, httpReq, handler)
 }
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/errors"
 	"github.com/kingfs/godify/models"
 )
 
@@ -142,3 +147,429 @@ func TestCompletion(t *testing.T) {
 		t.Errorf("Expected answer 'This is a completion response.', got %s", resp.Answer)
 	}
 }
+
+func TestChatStreamTypedHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat-messages" {
+			t.Errorf("Expected path /v1/chat-messages, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte("data: {\"event\": \"message\", \"message_id\": \"msg-1\", \"conversation_id\": \"conv-1\", \"answer\": \"Hi\"}\n\n" +
+			"data: {\"event\": \"message\", \"message_id\": \"msg-1\", \"conversation_id\": \"conv-1\", \"answer\": \" there\"}\n\n" +
+			"data: {\"event\": \"message_end\", \"message_id\": \"msg-1\", \"conversation_id\": \"conv-1\", \"metadata\": {\"usage\": {\"total_tokens\": 7}}}\n\n"))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var answer string
+	var end *models.MessageEndEvent
+	handler := &client.TypedSSEHandler{
+		OnMessage: func(event *models.MessageEvent) error {
+			answer += event.Answer
+			return nil
+		},
+		OnMessageEnd: func(event *models.MessageEndEvent) error {
+			end = event
+			return nil
+		},
+	}
+
+	c := NewClient("test-token", server.URL)
+	err := c.ChatStream(context.Background(), &ChatRequest{Query: "Hello", User: "test-user"}, handler)
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if answer != "Hi there" {
+		t.Errorf("Expected answer 'Hi there', got %s", answer)
+	}
+
+	if end == nil || end.Metadata.Usage == nil || end.Metadata.Usage.TotalTokens != 7 {
+		t.Errorf("Expected message_end with 7 total tokens, got %+v", end)
+	}
+}
+
+func TestGetConversations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/conversations" {
+			t.Errorf("Expected path /v1/conversations, got %s", r.URL.Path)
+		}
+
+		if user := r.URL.Query().Get("user"); user != "test-user" {
+			t.Errorf("Expected user 'test-user', got %s", user)
+		}
+
+		if limit := r.URL.Query().Get("limit"); limit != "20" {
+			t.Errorf("Expected limit '20', got %s", limit)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{
+			"limit": 20,
+			"has_more": false,
+			"data": [
+				{
+					"id": "conv-1",
+					"name": "Test Conversation",
+					"inputs": {},
+					"introduction": "",
+					"created_at": 1700000000,
+					"updated_at": 1700000000
+				}
+			]
+		}`))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	resp, err := client.GetConversations(context.Background(), "test-user", "", 20, "")
+	if err != nil {
+		t.Fatalf("GetConversations failed: %v", err)
+	}
+
+	if len(resp.Data) != 1 || resp.Data[0].ID != "conv-1" {
+		t.Errorf("Expected conversation conv-1, got %+v", resp.Data)
+	}
+}
+
+func TestRenameConversation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/conversations/conv-1/name" {
+			t.Errorf("Expected path /v1/conversations/conv-1/name, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+
+		if body["user"] != "test-user" || body["name"] != "New Name" {
+			t.Errorf("Expected user and name in body, got %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"id": "conv-1", "name": "New Name"}`))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	name := "New Name"
+	client := NewClient("test-token", server.URL)
+	resp, err := client.RenameConversation(context.Background(), "conv-1", "test-user", &models.ConversationRenameRequest{Name: &name})
+	if err != nil {
+		t.Fatalf("RenameConversation failed: %v", err)
+	}
+
+	if resp.Name != "New Name" {
+		t.Errorf("Expected name 'New Name', got %s", resp.Name)
+	}
+}
+
+func TestSendMessageFeedback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/msg-1/feedbacks" {
+			t.Errorf("Expected path /v1/messages/msg-1/feedbacks, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+
+		if body["user"] != "test-user" || body["rating"] != "like" {
+			t.Errorf("Expected user and rating in body, got %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"result": "success"}`))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	rating := "like"
+	client := NewClient("test-token", server.URL)
+	err := client.SendMessageFeedback(context.Background(), "msg-1", "test-user", &models.MessageFeedbackRequest{Rating: &rating})
+	if err != nil {
+		t.Fatalf("SendMessageFeedback failed: %v", err)
+	}
+}
+
+func TestRunWorkflowStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/workflows/run" {
+			t.Errorf("Expected path /v1/workflows/run, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if body["response_mode"] != "streaming" {
+			t.Errorf("Expected response_mode 'streaming', got %v", body["response_mode"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte("data: {\"event\": \"workflow_started\", \"task_id\": \"task-1\", \"workflow_run_id\": \"run-1\", \"data\": {\"id\": \"run-1\", \"workflow_id\": \"wf-1\"}}\n\n" +
+			"data: {\"event\": \"node_finished\", \"task_id\": \"task-1\", \"workflow_run_id\": \"run-1\", \"data\": {\"id\": \"exec-1\", \"node_id\": \"llm\", \"node_type\": \"llm\", \"status\": \"succeeded\"}}\n\n" +
+			"data: {\"event\": \"text_chunk\", \"task_id\": \"task-1\", \"workflow_run_id\": \"run-1\", \"data\": {\"text\": \"Hi\", \"from_variable_selector\": [\"llm\", \"text\"]}}\n\n" +
+			"data: {\"event\": \"iteration_next\", \"task_id\": \"task-1\", \"workflow_run_id\": \"run-1\", \"data\": {\"id\": \"exec-2\", \"node_id\": \"iter\", \"index\": 1}}\n\n" +
+			"data: {\"event\": \"workflow_finished\", \"task_id\": \"task-1\", \"workflow_run_id\": \"run-1\", \"data\": {\"id\": \"run-1\", \"status\": \"succeeded\", \"total_steps\": 3}}\n\n"))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	var nodes []string
+	var text string
+	var iterations []int
+	var finished *models.WorkflowFinishedEvent
+	handler := &client.TypedSSEHandler{
+		OnNodeFinished: func(event *models.NodeFinishedEvent) error {
+			nodes = append(nodes, event.Data.NodeID)
+			return nil
+		},
+		OnTextChunk: func(event *models.TextChunkEvent) error {
+			text += event.Data.Text
+			return nil
+		},
+		OnIteration: func(event *models.IterationEvent) error {
+			if event.EventType() == models.StreamEventIterationNext {
+				iterations = append(iterations, event.Data.Index)
+			}
+			return nil
+		},
+		OnWorkflowFinished: func(event *models.WorkflowFinishedEvent) error {
+			finished = event
+			return nil
+		},
+	}
+
+	c := NewClient("test-token", server.URL)
+	err := c.RunWorkflowStream(context.Background(), &models.WorkflowRunRequest{
+		Inputs: map[string]interface{}{"query": "Hello"},
+		User:   "test-user",
+	}, handler)
+	if err != nil {
+		t.Fatalf("RunWorkflowStream failed: %v", err)
+	}
+
+	if len(nodes) != 1 || nodes[0] != "llm" {
+		t.Errorf("Expected node_finished for 'llm', got %v", nodes)
+	}
+
+	if text != "Hi" {
+		t.Errorf("Expected text 'Hi', got %s", text)
+	}
+
+	if len(iterations) != 1 || iterations[0] != 1 {
+		t.Errorf("Expected iteration index [1], got %v", iterations)
+	}
+
+	if finished == nil || finished.WorkflowRunID != "run-1" || finished.Data.TotalSteps != 3 {
+		t.Errorf("Expected workflow_finished for run-1 with 3 steps, got %+v", finished)
+	}
+}
+
+func TestGetWorkflowLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/workflows/logs" {
+			t.Errorf("Expected path /v1/workflows/logs, got %s", r.URL.Path)
+		}
+
+		if status := r.URL.Query().Get("status"); status != "failed" {
+			t.Errorf("Expected status 'failed', got %s", status)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{
+			"page": 1,
+			"limit": 20,
+			"total": 1,
+			"has_more": false,
+			"data": [{
+				"id": "log-1",
+				"workflow_run": {"id": "run-1", "status": "failed", "error": "boom", "total_steps": 2},
+				"created_from": "service-api",
+				"created_by_role": "end_user",
+				"created_by_end_user": {"id": "eu-1", "type": "service_api", "session_id": "test-user"},
+				"created_at": 1705407629
+			}]
+		}`))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("test-token", server.URL)
+	logs, err := c.GetWorkflowLogs(context.Background(), "", "failed", 1, 20)
+	if err != nil {
+		t.Fatalf("GetWorkflowLogs failed: %v", err)
+	}
+
+	if len(logs.Data) != 1 || logs.Data[0].WorkflowRun == nil || logs.Data[0].WorkflowRun.Error != "boom" {
+		t.Fatalf("Expected one failed workflow run, got %+v", logs.Data)
+	}
+
+	if logs.Data[0].CreatedByEndUser == nil || logs.Data[0].CreatedByEndUser.SessionID != "test-user" {
+		t.Errorf("Expected end user session 'test-user', got %+v", logs.Data[0].CreatedByEndUser)
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/files/upload" {
+			t.Errorf("Expected path /v1/files/upload, got %s", r.URL.Path)
+		}
+
+		if user := r.FormValue("user"); user != "test-user" {
+			t.Errorf("Expected user 'test-user', got %s", user)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Expected file field: %v", err)
+			return
+		}
+		defer file.Close()
+		if header.Filename != "cat.png" {
+			t.Errorf("Expected filename 'cat.png', got %s", header.Filename)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, err = w.Write([]byte(`{"id": "file-1", "name": "cat.png", "size": 3, "extension": "png", "mime_type": "image/png", "created_at": 1705407629}`))
+		if err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("test-token", server.URL)
+	upload, err := c.UploadFile(context.Background(), "cat.png", []byte("png"), "test-user")
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	file := upload.InputFile()
+	if file["type"] != "image" || file["transfer_method"] != "local_file" || file["upload_file_id"] != "file-1" {
+		t.Errorf("Unexpected input file entry: %v", file)
+	}
+}
+
+func TestTextToAudio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/text-to-audio" {
+			t.Errorf("Expected path /v1/text-to-audio, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte("ID3audio")); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("test-token", server.URL)
+	audio, err := c.TextToAudio(context.Background(), &TextToAudioRequest{Text: "Hello", User: "test-user"})
+	if err != nil {
+		t.Fatalf("TextToAudio failed: %v", err)
+	}
+
+	if audio.ContentType != "audio/mpeg" || string(audio.Audio) != "ID3audio" {
+		t.Errorf("Unexpected audio response: %s %q", audio.ContentType, audio.Audio)
+	}
+}
+
+func TestAllMessages(t *testing.T) {
+	var firstIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		firstID := r.URL.Query().Get("first_id")
+		firstIDs = append(firstIDs, firstID)
+
+		w.Header().Set("Content-Type", "application/json")
+		if firstID == "" {
+			w.Write([]byte(`{"data": [{"id": "msg-3"}, {"id": "msg-4"}], "has_more": true, "limit": 2}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "msg-1"}, {"id": "msg-2"}], "has_more": true, "limit": 2}`))
+	}))
+	defer server.Close()
+
+	opts := &client.PagerOptions{PageSize: 2, MaxItems: 3}
+	client := NewClient("test-token", server.URL)
+	var ids []string
+	for message, err := range client.AllMessages(context.Background(), "conv-1", "test-user", opts) {
+		if err != nil {
+			t.Fatalf("AllMessages failed: %v", err)
+		}
+		ids = append(ids, message.ID)
+	}
+
+	if len(ids) != 3 || ids[2] != "msg-1" {
+		t.Errorf("Expected messages [msg-3 msg-4 msg-1], got %v", ids)
+	}
+	if len(firstIDs) != 2 || firstIDs[1] != "msg-3" {
+		t.Errorf("Expected second request with first_id msg-3, got %q", firstIDs)
+	}
+}
+
+func TestUploadFileDecodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html>gateway</html>`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	_, err := client.UploadFile(context.Background(), "notes.txt", []byte("hello"), "test-user")
+
+	var decodeErr *errors.DecodeError
+	if !stderrors.As(err, &decodeErr) {
+		t.Fatalf("Expected *errors.DecodeError, got %T: %v", err, err)
+	}
+	if decodeErr.Method != "POST" || decodeErr.Path != "/files/upload" || decodeErr.RequestID == "" || decodeErr.Attempts != 1 {
+		t.Errorf("Expected request info on decode error, got %+v", decodeErr.RequestInfo)
+	}
+	if decodeErr.Body != "<html>gateway</html>" {
+		t.Errorf("Expected response body snippet, got %q", decodeErr.Body)
+	}
+}
+
+func TestUploadFileReaderKeepsUserField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse multipart form: %v", err)
+		}
+		if user := r.FormValue("user"); user != "test-user" {
+			t.Errorf("Expected user 'test-user', got %s", user)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "file-1"}`))
+	}))
+	defer server.Close()
+
+	opts := &client.UploadOptions{ExtraFields: map[string]string{"user": "someone-else"}}
+	client := NewClient("test-token", server.URL)
+	if _, err := client.UploadFileReader(context.Background(), "notes.txt", strings.NewReader("hello"), "test-user", opts); err != nil {
+		t.Fatalf("UploadFileReader failed: %v", err)
+	}
+}
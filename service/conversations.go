@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+// ============ 对话管理 ============
+
+// GetConversations 获取用户的对话列表
+func (c *Client) GetConversations(ctx context.Context, user string, lastID string, limit int, sortBy string) (*models.ConversationListResponse, error) {
+	query := url.Values{"user": {user}}
+	if lastID != "" {
+		query.Set("last_id", lastID)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if sortBy != "" {
+		query.Set("sort_by", sortBy)
+	}
+
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/conversations",
+		Query:  query,
+	}
+
+	var result models.ConversationListResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// AllConversations 遍历用户的全部对话，按last_id自动翻页
+func (c *Client) AllConversations(ctx context.Context, user string, sortBy string, opts *client.PagerOptions) iter.Seq2[models.Conversation, error] {
+	return client.NewPager(func(ctx context.Context, _ int, lastID string, limit int) (*client.Page[models.Conversation], error) {
+		resp, err := c.GetConversations(ctx, user, lastID, limit, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.Conversation]{Items: resp.Data, HasMore: resp.HasMore, Cursor: resp.LastID()}, nil
+	}, opts).Items(ctx)
+}
+
+// DeleteConversation 删除对话
+func (c *Client) DeleteConversation(ctx context.Context, conversationID string, user string) error {
+	req := &client.Request{
+		Method: "DELETE",
+		Path:   "/conversations/" + conversationID,
+		Body:   map[string]string{"user": user},
+	}
+
+	var result models.ConversationOperationResponse
+	return c.baseClient.DoJSON(ctx, req, &result)
+}
+
+// RenameConversation 重命名对话
+func (c *Client) RenameConversation(ctx context.Context, conversationID string, user string, request *models.ConversationRenameRequest) (*models.SimpleConversation, error) {
+	body := struct {
+		*models.ConversationRenameRequest
+		User string `json:"user"`
+	}{request, user}
+
+	req := &client.Request{
+		Method: "POST",
+		Path:   "/conversations/" + conversationID + "/name",
+		Body:   body,
+	}
+
+	var result models.SimpleConversation
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// GetConversationVariables 获取对话变量
+func (c *Client) GetConversationVariables(ctx context.Context, conversationID string, user string, lastID string, limit int, variableName string) (*models.ConversationVariableListResponse, error) {
+	query := url.Values{"user": {user}}
+	if lastID != "" {
+		query.Set("last_id", lastID)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if variableName != "" {
+		query.Set("variable_name", variableName)
+	}
+
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/conversations/" + conversationID + "/variables",
+		Query:  query,
+	}
+
+	var result models.ConversationVariableListResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// ============ 消息管理 ============
+
+// GetMessages 获取对话的历史消息
+func (c *Client) GetMessages(ctx context.Context, conversationID string, user string, firstID string, limit int) (*models.MessageListResponse, error) {
+	query := url.Values{
+		"conversation_id": {conversationID},
+		"user":            {user},
+	}
+	if firstID != "" {
+		query.Set("first_id", firstID)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/messages",
+		Query:  query,
+	}
+
+	var result models.MessageListResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// AllMessages 遍历对话的全部历史消息，按first_id向更早的消息翻页
+func (c *Client) AllMessages(ctx context.Context, conversationID string, user string, opts *client.PagerOptions) iter.Seq2[models.Message, error] {
+	return client.NewPager(func(ctx context.Context, _ int, firstID string, limit int) (*client.Page[models.Message], error) {
+		resp, err := c.GetMessages(ctx, conversationID, user, firstID, limit)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.Message]{Items: resp.Data, HasMore: resp.HasMore, Cursor: resp.FirstID()}, nil
+	}, opts).Items(ctx)
+}
+
+// SendMessageFeedback 发送消息反馈
+func (c *Client) SendMessageFeedback(ctx context.Context, messageID string, user string, feedback *models.MessageFeedbackRequest) error {
+	body := struct {
+		*models.MessageFeedbackRequest
+		User string `json:"user"`
+	}{feedback, user}
+
+	req := &client.Request{
+		Method: "POST",
+		Path:   "/messages/" + messageID + "/feedbacks",
+		Body:   body,
+	}
+
+	var result map[string]string
+	return c.baseClient.DoJSON(ctx, req, &result)
+}
+
+// GetSuggestedQuestions 获取下一轮建议问题
+func (c *Client) GetSuggestedQuestions(ctx context.Context, messageID string, user string) (*models.SuggestedQuestionsResponse, error) {
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/messages/" + messageID + "/suggested",
+		Query:  url.Values{"user": {user}},
+	}
+
+	var result models.SuggestedQuestionsResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+// ============ 文件与音频 ============
+
+// UploadFile 上传文件，返回的文件可通过 FileUpload.InputFile 用于请求的files字段
+func (c *Client) UploadFile(ctx context.Context, filename string, fileData []byte, user string) (*models.FileUpload, error) {
+	resp, err := c.baseClient.UploadFile(ctx, "/files/upload", "file", filename, fileData, map[string]string{"user": user})
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.FileUpload
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UploadFileReader 以流式方式上传文件，适用于大文件上传
+func (c *Client) UploadFileReader(ctx context.Context, filename string, r io.Reader, user string, opts *client.UploadOptions) (*models.FileUpload, error) {
+	uploadOpts := opts.WithExtraFields(map[string]string{"user": user})
+	resp, err := c.baseClient.UploadFileReader(ctx, "/files/upload", "file", filename, r, uploadOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.FileUpload
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AudioToText 语音转文字
+func (c *Client) AudioToText(ctx context.Context, filename string, audioData []byte, user string) (*models.AudioToTextResponse, error) {
+	resp, err := c.baseClient.UploadFile(ctx, "/audio-to-text", "file", filename, audioData, map[string]string{"user": user})
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.AudioToTextResponse
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TextToAudioRequest 文字转语音请求，MessageID优先于Text
+type TextToAudioRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Voice     string `json:"voice,omitempty"`
+	User      string `json:"user"`
+}
+
+// TextToAudio 文字转语音，返回完整的音频数据
+func (c *Client) TextToAudio(ctx context.Context, req *TextToAudioRequest) (*models.TextToAudioResponse, error) {
+	httpReq := &client.Request{
+		Method: "POST",
+		Path:   "/text-to-audio",
+		Body:   req,
+	}
+
+	resp, err := c.baseClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TextToAudioResponse{
+		ContentType: resp.Headers.Get("Content-Type"),
+		Audio:       resp.Body,
+	}, nil
+}
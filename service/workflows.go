@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+
+	"github.com/kingfs/godify/client"
+	"github.com/kingfs/godify/models"
+)
+
+// ============ 工作流执行 ============
+
+// RunWorkflow 以阻塞模式运行工作流
+func (c *Client) RunWorkflow(ctx context.Context, req *models.WorkflowRunRequest) (*models.WorkflowRunResponse, error) {
+	if req.ResponseMode == "" {
+		req.ResponseMode = models.ResponseModeBlocking
+	}
+
+	httpReq := &client.Request{
+		Method: "POST",
+		Path:   "/workflows/run",
+		Body:   req,
+	}
+
+	var result models.WorkflowRunResponse
+	err := c.baseClient.DoJSON(ctx, httpReq, &result)
+	return &result, err
+}
+
+// RunWorkflowStream 以流式模式运行工作流
+// handler 可使用 *client.TypedSSEHandler 接收节点级别的类型化事件
+func (c *Client) RunWorkflowStream(ctx context.Context, req *models.WorkflowRunRequest, handler client.SSEHandler) error {
+	return c.baseClient.StreamResponse(ctx, workflowStreamRequest(req), handler)
+}
+
+// RunWorkflowStreamChan 以通道形式返回工作流流式事件，流结束或出错后通道关闭
+func (c *Client) RunWorkflowStreamChan(ctx context.Context, req *models.WorkflowRunRequest) <-chan client.StreamResult {
+	return c.baseClient.StreamChan(ctx, workflowStreamRequest(req))
+}
+
+// RunWorkflowStreamIter 以迭代器形式返回工作流流式事件
+func (c *Client) RunWorkflowStreamIter(ctx context.Context, req *models.WorkflowRunRequest) iter.Seq2[models.StreamEvent, error] {
+	return c.baseClient.StreamEvents(ctx, workflowStreamRequest(req))
+}
+
+// workflowStreamRequest 构建流式工作流请求
+func workflowStreamRequest(req *models.WorkflowRunRequest) *client.Request {
+	// 强制设置为流式模式
+	req.ResponseMode = models.ResponseModeStreaming
+
+	return &client.Request{
+		Method: "POST",
+		Path:   "/workflows/run",
+		Body:   req,
+	}
+}
+
+// StopWorkflowTask 停止工作流任务，仅支持流式模式
+func (c *Client) StopWorkflowTask(ctx context.Context, taskID string, user string) error {
+	httpReq := &client.Request{
+		Method: "POST",
+		Path:   "/workflows/tasks/" + taskID + "/stop",
+		Body:   map[string]string{"user": user},
+	}
+
+	var result models.WorkflowStopResponse
+	return c.baseClient.DoJSON(ctx, httpReq, &result)
+}
+
+// GetWorkflowRun 获取工作流运行详情
+func (c *Client) GetWorkflowRun(ctx context.Context, workflowRunID string) (*models.WorkflowRun, error) {
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/workflows/run/" + workflowRunID,
+	}
+
+	var result models.WorkflowRun
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
+
+// GetWorkflowLogs 获取工作流日志
+// status 可选值：succeeded、failed、stopped
+func (c *Client) GetWorkflowLogs(ctx context.Context, keyword string, status string, page int, limit int) (*models.WorkflowAppLogListResponse, error) {
+	query := url.Values{}
+	if keyword != "" {
+		query.Set("keyword", keyword)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	req := &client.Request{
+		Method: "GET",
+		Path:   "/workflows/logs",
+		Query:  query,
+	}
+
+	var result models.WorkflowAppLogListResponse
+	err := c.baseClient.DoJSON(ctx, req, &result)
+	return &result, err
+}
@@ -3,9 +3,11 @@ package web
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"iter"
 	"mime/multipart"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -14,35 +16,69 @@ import (
 )
 
 // Client Web API客户端
+//
+// passport按终端用户缓存，可通过WithUser指定请求所属的用户，Client可在多个goroutine间共享。
 type Client struct {
-	baseClient  *client.BaseClient
-	appCode     string
-	appPassport string
+	baseClient *client.BaseClient
+	auth       *client.PassportAuth
+	passports  *passportCache
 }
 
 // NewClient 创建Web API客户端 (需要app code而不是api key)
-func NewClient(baseURL string) *Client {
+// 默认超时30秒、重试3次并跳过证书校验，可通过opts修改
+func NewClient(baseURL string, opts ...client.Option) *Client {
+	// 认证器只携带X-App-Code，passport由passportMiddleware按用户附加
+	c := &Client{
+		auth:      client.NewPassportAuth(""),
+		passports: newPassportCache(),
+	}
 	config := &client.ClientConfig{
-		BaseURL:    baseURL + "/api",
-		AuthType:   client.AuthTypeBearer,
-		Token:      "", // 初始为空，通过passport获取
-		SkipTLS:    true,
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
+		BaseURL:       baseURL + "/api",
+		SkipTLS:       true,
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		Authenticator: c.auth,
+		// passportMiddleware始终位于最外层，WithApp依赖这一位置
+		Middlewares: []client.Middleware{c.passportMiddleware},
 	}
 
-	return &Client{
-		baseClient: client.NewBaseClient(config),
+	c.baseClient = client.NewBaseClient(config, opts...)
+	return c
+}
+
+// Use 为客户端追加请求中间件
+func (c *Client) Use(middlewares ...client.Middleware) *Client {
+	c.baseClient.Use(middlewares...)
+	return c
+}
+
+// WithApp 派生访问另一个应用的客户端，共享连接池与中间件，passport缓存相互独立
+func (c *Client) WithApp(appCode string) *Client {
+	d := &Client{
+		auth:      client.NewPassportAuth(appCode),
+		passports: newPassportCache(),
 	}
+	d.passports.setRenewBefore(c.passports.getRenewBefore())
+	d.baseClient = c.baseClient.Derive(func(config *client.ClientConfig) {
+		config.Authenticator = d.auth
+		// passportMiddleware在NewClient中位于首位，替换为绑定派生客户端的版本
+		config.Middlewares[0] = d.passportMiddleware
+	})
+	return d
 }
 
+// WithAppCode 切换当前客户端访问的应用，已缓存的passport属于原应用，会被全部清除
+//
+// 需要同时访问多个应用时使用WithApp派生客户端，各自的passport缓存相互独立。
 func (c *Client) WithAppCode(appCode string) *Client {
-	c.appCode = appCode
+	c.auth.SetAppCode(appCode)
+	c.passports.clear()
 	return c
 }
 
+// WithAppPassport 设置匿名用户的passport
 func (c *Client) WithAppPassport(appPassport string) *Client {
-	c.appPassport = appPassport
+	c.passports.store("", appPassport)
 	return c
 }
 
@@ -56,54 +92,38 @@ type PassportResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
-// GetPassport 获取访问令牌
+// GetPassport 获取终端用户的访问令牌并缓存，userID为空时获取匿名用户的passport
 func (c *Client) GetPassport(ctx context.Context, userID string) (string, error) {
+	token, err := c.fetchPassport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	c.passports.store(userID, token)
+	return token, nil
+}
+
+// fetchPassport 请求passport接口
+func (c *Client) fetchPassport(ctx context.Context, userID string) (string, error) {
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/passport",
-		Headers: map[string]string{
-			"X-App-Code": c.appCode,
-		},
-		Query: map[string]string{},
+		Query:  url.Values{},
 	}
 
 	if userID != "" {
-		req.Query["user_id"] = userID
+		req.Query.Set("user_id", userID)
 	}
 
 	var result PassportResponse
 	if err := c.baseClient.DoJSON(ctx, req, &result); err != nil {
 		return "", fmt.Errorf("failed to get passport: %w", err)
 	}
-
-	c.appPassport = result.AccessToken
-	c.baseClient.WithToken(result.AccessToken)
 	return result.AccessToken, nil
 }
 
-// ensureAuthenticated 确保已认证
-func (c *Client) ensureAuthenticated(ctx context.Context) error {
-	if c.appPassport == "" {
-		_, err := c.GetPassport(ctx, "")
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// doAuthenticatedRequest 执行认证请求
+// doAuthenticatedRequest 执行认证请求，passport由passportMiddleware附加
 func (c *Client) doAuthenticatedRequest(ctx context.Context, req *client.Request, result interface{}) error {
-	if err := c.ensureAuthenticated(ctx); err != nil {
-		return err
-	}
-
-	if req.Headers == nil {
-		req.Headers = make(map[string]string)
-	}
-	req.Headers["X-App-Code"] = c.appCode
-	req.Headers["X-App-Passport"] = c.appPassport
-
 	return c.baseClient.DoJSON(ctx, req, result)
 }
 
@@ -133,12 +153,12 @@ func (c *Client) GetAppMeta(ctx context.Context) (*models.AppMeta, error) {
 
 // GetWebAppAccessMode 检查Web应用访问模式
 func (c *Client) GetWebAppAccessMode(ctx context.Context, appID string, appCode string) (*models.WebAppAccessMode, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if appID != "" {
-		query["appId"] = appID
+		query.Set("appId", appID)
 	}
 	if appCode != "" {
-		query["appCode"] = appCode
+		query.Set("appCode", appCode)
 	}
 
 	req := &client.Request{
@@ -158,7 +178,7 @@ func (c *Client) CheckWebAppPermission(ctx context.Context, appID string) (*mode
 	req := &client.Request{
 		Method: "GET",
 		Path:   "/webapp/permission",
-		Query:  map[string]string{"appId": appID},
+		Query:  url.Values{"appId": {appID}},
 	}
 
 	var result models.WebAppPermission
@@ -250,6 +270,7 @@ func (c *Client) ChatStop(ctx context.Context, taskID string) error {
 }
 
 // ChatStream 流式聊天对话
+// handler 可使用 *client.TypedSSEHandler 按事件类型接收解析后的事件
 func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, handler client.SSEHandler) error {
 	// 强制设置为流式模式
 	req.ResponseMode = models.ResponseModeStreaming
@@ -258,24 +279,17 @@ func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, handler clien
 		req.RetrieverFrom = "web_app"
 	}
 
-	// 确保已认证
-	if err := c.ensureAuthenticated(ctx); err != nil {
-		return err
-	}
-
 	httpReq := &client.Request{
 		Method: "POST",
 		Path:   "/chat-messages",
 		Body:   req,
-		Headers: map[string]string{
-			"X-App-Code": c.appCode,
-		},
 	}
 
 	return c.baseClient.StreamResponse(ctx, httpReq, handler)
 }
 
 // CompletionStream 流式文本补全
+// handler 可使用 *client.TypedSSEHandler 按事件类型接收解析后的事件
 func (c *Client) CompletionStream(ctx context.Context, req *CompletionRequest, handler client.SSEHandler) error {
 	// 强制设置为流式模式
 	req.ResponseMode = models.ResponseModeStreaming
@@ -284,18 +298,10 @@ func (c *Client) CompletionStream(ctx context.Context, req *CompletionRequest, h
 		req.RetrieverFrom = "web_app"
 	}
 
-	// 确保已认证
-	if err := c.ensureAuthenticated(ctx); err != nil {
-		return err
-	}
-
 	httpReq := &client.Request{
 		Method: "POST",
 		Path:   "/completion-messages",
 		Body:   req,
-		Headers: map[string]string{
-			"X-App-Code": c.appCode,
-		},
 	}
 
 	return c.baseClient.StreamResponse(ctx, httpReq, handler)
@@ -303,18 +309,18 @@ func (c *Client) CompletionStream(ctx context.Context, req *CompletionRequest, h
 
 // GetConversations 获取对话列表
 func (c *Client) GetConversations(ctx context.Context, lastID string, limit int, pinned *bool, sortBy string) (*models.ConversationListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if lastID != "" {
-		query["last_id"] = lastID
+		query.Set("last_id", lastID)
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if pinned != nil {
-		query["pinned"] = strconv.FormatBool(*pinned)
+		query.Set("pinned", strconv.FormatBool(*pinned))
 	}
 	if sortBy != "" {
-		query["sort_by"] = sortBy
+		query.Set("sort_by", sortBy)
 	}
 
 	req := &client.Request{
@@ -328,6 +334,17 @@ func (c *Client) GetConversations(ctx context.Context, lastID string, limit int,
 	return &result, err
 }
 
+// AllConversations 遍历全部对话，按last_id自动翻页
+func (c *Client) AllConversations(ctx context.Context, pinned *bool, sortBy string, opts *client.PagerOptions) iter.Seq2[models.Conversation, error] {
+	return client.NewPager(func(ctx context.Context, _ int, lastID string, limit int) (*client.Page[models.Conversation], error) {
+		resp, err := c.GetConversations(ctx, lastID, limit, pinned, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.Conversation]{Items: resp.Data, HasMore: resp.HasMore, Cursor: resp.LastID()}, nil
+	}, opts).Items(ctx)
+}
+
 // DeleteConversation 删除对话
 func (c *Client) DeleteConversation(ctx context.Context, conversationID string) error {
 	req := &client.Request{
@@ -376,12 +393,12 @@ func (c *Client) UnpinConversation(ctx context.Context, conversationID string) e
 
 // GetMessages 获取消息列表
 func (c *Client) GetMessages(ctx context.Context, conversationID string, firstID string, limit int) (*models.MessageListResponse, error) {
-	query := make(map[string]string)
+	query := url.Values{}
 	if firstID != "" {
-		query["first_id"] = firstID
+		query.Set("first_id", firstID)
 	}
 	if limit > 0 {
-		query["limit"] = strconv.Itoa(limit)
+		query.Set("limit", strconv.Itoa(limit))
 	}
 
 	req := &client.Request{
@@ -395,6 +412,17 @@ func (c *Client) GetMessages(ctx context.Context, conversationID string, firstID
 	return &result, err
 }
 
+// AllMessages 遍历对话的全部历史消息，按first_id向更早的消息翻页
+func (c *Client) AllMessages(ctx context.Context, conversationID string, opts *client.PagerOptions) iter.Seq2[models.Message, error] {
+	return client.NewPager(func(ctx context.Context, _ int, firstID string, limit int) (*client.Page[models.Message], error) {
+		resp, err := c.GetMessages(ctx, conversationID, firstID, limit)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[models.Message]{Items: resp.Data, HasMore: resp.HasMore, Cursor: resp.FirstID()}, nil
+	}, opts).Items(ctx)
+}
+
 // SendMessageFeedback 发送消息反馈
 func (c *Client) SendMessageFeedback(ctx context.Context, messageID string, feedback *models.MessageFeedbackRequest) error {
 	req := &client.Request{
@@ -409,8 +437,8 @@ func (c *Client) SendMessageFeedback(ctx context.Context, messageID string, feed
 
 // GetMessageMoreLikeThis 获取类似消息
 func (c *Client) GetMessageMoreLikeThis(ctx context.Context, messageID string, responseMode models.ResponseMode) (*models.GenerateResponse, error) {
-	query := map[string]string{
-		"response_mode": string(responseMode),
+	query := url.Values{
+		"response_mode": {string(responseMode)},
 	}
 
 	req := &client.Request{
@@ -438,11 +466,6 @@ func (c *Client) GetSuggestedQuestions(ctx context.Context, messageID string) (*
 
 // UploadFile 上传文件
 func (c *Client) UploadFile(ctx context.Context, filename string, fileData []byte, source string) (*models.FileUpload, error) {
-	// 确保已认证
-	if err := c.ensureAuthenticated(ctx); err != nil {
-		return nil, err
-	}
-
 	extraFields := make(map[string]string)
 	if source != "" {
 		extraFields["source"] = source
@@ -453,20 +476,34 @@ func (c *Client) UploadFile(ctx context.Context, filename string, fileData []byt
 	if err != nil {
 		return nil, err
 	}
-	if err := json.Unmarshal(resp.Body, &result); err != nil {
+	if err := resp.DecodeJSON(&result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
-// AudioToText 语音转文字
-func (c *Client) AudioToText(ctx context.Context, audioData []byte, filename string) (map[string]interface{}, error) {
-	// 确保已认证
-	if err := c.ensureAuthenticated(ctx); err != nil {
+// UploadFileReader 以流式方式上传文件，适用于大文件上传
+func (c *Client) UploadFileReader(ctx context.Context, filename string, r io.Reader, source string, opts *client.UploadOptions) (*models.FileUpload, error) {
+	var fields map[string]string
+	if source != "" {
+		fields = map[string]string{"source": source}
+	}
+	resp, err := c.baseClient.UploadFileReader(ctx, "/files/upload", "file", filename, r, opts.WithExtraFields(fields))
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.FileUpload
+	if err := resp.DecodeJSON(&result); err != nil {
 		return nil, err
 	}
 
+	return &result, nil
+}
+
+// AudioToText 语音转文字
+func (c *Client) AudioToText(ctx context.Context, audioData []byte, filename string) (map[string]interface{}, error) {
 	_, err := c.authenticatedUploadFile(ctx, "/audio-to-text", "file", filename, audioData, nil)
 	if err != nil {
 		return nil, err
@@ -557,9 +594,7 @@ func (c *Client) authenticatedUploadFile(ctx context.Context, path string, field
 		Method: "POST",
 		Path:   path,
 		Headers: map[string]string{
-			"Content-Type":   writer.FormDataContentType(),
-			"X-App-Code":     c.appCode,
-			"X-App-Passport": c.appPassport,
+			"Content-Type": writer.FormDataContentType(),
 		},
 		Body: buf.Bytes(),
 	}
@@ -208,3 +208,36 @@ func TestWebChat(t *testing.T) {
 		t.Errorf("Expected answer 'Hello from web API!', got %s", resp.Answer)
 	}
 }
+
+func TestWebAuthHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := r.Header.Get("X-App-Code"); code != "test-app-code" {
+			t.Errorf("Expected X-App-Code 'test-app-code' on %s, got %s", r.URL.Path, code)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/passport":
+			if auth := r.Header.Get("Authorization"); auth != "" {
+				t.Errorf("Expected no Authorization header before passport is issued, got %s", auth)
+			}
+			w.Write([]byte(`{"access_token": "passport-1"}`))
+		case "/api/parameters":
+			if auth := r.Header.Get("Authorization"); auth != "Bearer passport-1" {
+				t.Errorf("Expected Authorization 'Bearer passport-1', got %s", auth)
+			}
+			if passport := r.Header.Get("X-App-Passport"); passport != "passport-1" {
+				t.Errorf("Expected X-App-Passport 'passport-1', got %s", passport)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL).WithAppCode("test-app-code")
+	if _, err := client.GetAppParameters(context.Background()); err != nil {
+		t.Fatalf("GetAppParameters failed: %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kingfs/godify/client"
+)
+
+// defaultPassportRenewBefore passport到期前提前续期的时间
+const defaultPassportRenewBefore = time.Minute
+
+// defaultPassportCacheSize 缓存的终端用户数上限，超出时先清理过期passport，再淘汰最久未使用的用户
+const defaultPassportCacheSize = 10000
+
+// publicPaths 不需要passport的接口
+var publicPaths = map[string]bool{
+	"/passport":           true,
+	"/webapp/access-mode": true,
+	"/webapp/permission":  true,
+}
+
+type userIDKey struct{}
+
+// WithUser 返回携带终端用户ID的context，请求将使用该用户的passport
+//
+// 未设置时使用匿名用户（空user_id）的passport。
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserFromContext 获取context中的终端用户ID
+func UserFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// passportEntry 单个终端用户的passport
+type passportEntry struct {
+	// mu 串行化同一用户的passport获取
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	// lastUsed 最近一次获取该缓存项的时间，由passportCache.mu保护
+	lastUsed time.Time
+}
+
+// valid 判断passport在renewBefore之后是否仍然有效，未携带过期时间的passport视为长期有效
+func (e *passportEntry) valid(now time.Time, renewBefore time.Duration) bool {
+	if e.token == "" {
+		return false
+	}
+	return e.expiresAt.IsZero() || now.Add(renewBefore).Before(e.expiresAt)
+}
+
+// expired 判断passport是否已过期，可在清理缓存时删除
+func (e *passportEntry) expired(now time.Time) bool {
+	return e.token != "" && !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func (e *passportEntry) set(token string) {
+	e.token = token
+	e.expiresAt, _ = parsePassportExpiry(token)
+}
+
+// passportCache 按终端用户ID缓存passport
+type passportCache struct {
+	mu          sync.Mutex
+	entries     map[string]*passportEntry
+	renewBefore time.Duration
+	maxEntries  int
+}
+
+func newPassportCache() *passportCache {
+	return &passportCache{
+		entries:     make(map[string]*passportEntry),
+		renewBefore: defaultPassportRenewBefore,
+		maxEntries:  defaultPassportCacheSize,
+	}
+}
+
+// entry 获取用户的缓存项，不存在时创建，缓存已满时先清理
+func (p *passportCache) entry(userID string) *passportEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	e, ok := p.entries[userID]
+	if !ok {
+		if len(p.entries) >= p.maxEntries {
+			p.prune(now)
+		}
+		e = &passportEntry{}
+		p.entries[userID] = e
+	}
+	e.lastUsed = now
+	return e
+}
+
+// prune 删除已过期的passport，仍然已满时淘汰最久未使用的用户，调用方需持有mu
+//
+// 正在获取passport的缓存项处于加锁状态，跳过以免打断进行中的请求。
+func (p *passportCache) prune(now time.Time) {
+	var oldestID string
+	var oldest *passportEntry
+	for userID, e := range p.entries {
+		if !e.mu.TryLock() {
+			continue
+		}
+		expired := e.expired(now)
+		e.mu.Unlock()
+
+		if expired {
+			delete(p.entries, userID)
+			continue
+		}
+		if oldest == nil || e.lastUsed.Before(oldest.lastUsed) {
+			oldestID, oldest = userID, e
+		}
+	}
+
+	if len(p.entries) >= p.maxEntries && oldest != nil {
+		delete(p.entries, oldestID)
+	}
+}
+
+func (p *passportCache) setRenewBefore(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.renewBefore = d
+}
+
+func (p *passportCache) getRenewBefore() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.renewBefore
+}
+
+// store 保存用户的passport
+func (p *passportCache) store(userID, token string) {
+	e := p.entry(userID)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.set(token)
+}
+
+// remove 删除用户的passport
+func (p *passportCache) remove(userID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, userID)
+}
+
+// clear 删除所有用户的passport
+func (p *passportCache) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = make(map[string]*passportEntry)
+}
+
+// invalidate 在passport仍为token时将其作废，已被其他请求更新过则保留
+func (p *passportCache) invalidate(userID, token string) {
+	e := p.entry(userID)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token == token {
+		e.token = ""
+	}
+}
+
+// parsePassportExpiry 从JWT格式的passport中解析exp，不是JWT或不含exp时返回false
+func parsePassportExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(claims.Exp), 0), true
+}
+
+// WithPassportRenewBefore 设置passport到期前提前续期的时间，默认1分钟
+func (c *Client) WithPassportRenewBefore(d time.Duration) *Client {
+	c.passports.setRenewBefore(d)
+	return c
+}
+
+// ForgetUser 删除终端用户缓存的passport
+func (c *Client) ForgetUser(userID string) {
+	c.passports.remove(userID)
+}
+
+// passport 获取用户有效的passport，缓存缺失或即将过期时重新获取
+func (c *Client) passport(ctx context.Context, userID string) (string, error) {
+	e := c.passports.entry(userID)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.valid(time.Now(), c.passports.getRenewBefore()) {
+		return e.token, nil
+	}
+
+	token, err := c.fetchPassport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	e.set(token)
+	return token, nil
+}
+
+// passportMiddleware 为请求附加终端用户的passport，返回401时重新获取passport并重试一次
+func (c *Client) passportMiddleware(next client.Handler) client.Handler {
+	return func(ctx context.Context, req *client.Request) (*client.Response, error) {
+		if publicPaths[req.Path] {
+			return next(ctx, req)
+		}
+
+		userID := UserFromContext(ctx)
+		passport, err := c.passport(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, withPassport(req, passport))
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		// io.Reader请求体只能读取一次，无法重试
+		if _, ok := req.Body.(io.Reader); ok {
+			return resp, nil
+		}
+
+		c.passports.invalidate(userID, passport)
+		renewed, err := c.passport(ctx, userID)
+		if err != nil {
+			return resp, nil
+		}
+		return next(ctx, withPassport(req, renewed))
+	}
+}
+
+// withPassport 返回携带passport请求头的请求副本
+func withPassport(req *client.Request, passport string) *client.Request {
+	if passport == "" {
+		return req
+	}
+
+	headers := make(map[string]string, len(req.Headers)+2)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	headers["Authorization"] = "Bearer " + passport
+	headers["X-App-Passport"] = passport
+
+	clone := *req
+	clone.Headers = headers
+	return &clone
+}
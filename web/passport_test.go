@@ -0,0 +1,236 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPassport 生成携带exp的JWT格式passport
+func testPassport(userID string, seq int, exp time.Time) string {
+	payload := fmt.Sprintf(`{"end_user_id": %q, "seq": %d, "exp": %d}`, userID, seq, exp.Unix())
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+// passportServer 按user_id签发passport，并拒绝已吊销的passport
+type passportServer struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	issued  map[string]int
+	current map[string]string
+	users   []string
+}
+
+func newPassportServer(ttl time.Duration) *passportServer {
+	return &passportServer{ttl: ttl, issued: map[string]int{}, current: map[string]string{}}
+}
+
+// revoke 吊销用户当前的passport
+func (s *passportServer) revoke(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current[userID] = ""
+}
+
+func (s *passportServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/api/passport" {
+		userID := r.URL.Query().Get("user_id")
+		s.issued[userID]++
+		token := testPassport(userID, s.issued[userID], time.Now().Add(s.ttl))
+		s.current[userID] = token
+		fmt.Fprintf(w, `{"access_token": %q}`, token)
+		return
+	}
+
+	passport := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	for userID, token := range s.current {
+		if token != "" && token == passport {
+			s.users = append(s.users, userID)
+			w.Write([]byte(`{}`))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write([]byte(`{"code": "unauthorized", "message": "invalid passport"}`))
+}
+
+func TestParsePassportExpiry(t *testing.T) {
+	exp := time.Unix(1893456000, 0)
+	got, ok := parsePassportExpiry(testPassport("user-1", 1, exp))
+	if !ok || !got.Equal(exp) {
+		t.Errorf("Expected expiry %v, got %v (ok=%v)", exp, got, ok)
+	}
+
+	if _, ok := parsePassportExpiry("opaque-token"); ok {
+		t.Error("Expected non-JWT passport to have no expiry")
+	}
+}
+
+func TestPassportPerUserCache(t *testing.T) {
+	backend := newPassportServer(time.Hour)
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	c := NewClient(server.URL).WithAppCode("test-app-code")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithUser(context.Background(), fmt.Sprintf("user-%d", i%2))
+			if _, err := c.GetAppParameters(ctx); err != nil {
+				t.Errorf("GetAppParameters failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if backend.issued["user-0"] != 1 || backend.issued["user-1"] != 1 {
+		t.Errorf("Expected one passport per user, got %v", backend.issued)
+	}
+	if len(backend.users) != 10 {
+		t.Errorf("Expected 10 authenticated requests, got %d", len(backend.users))
+	}
+}
+
+func TestPassportRenewal(t *testing.T) {
+	backend := newPassportServer(30 * time.Second)
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	// 有效期短于提前续期时间，每次请求前都会续期
+	c := NewClient(server.URL).WithAppCode("test-app-code").WithPassportRenewBefore(time.Minute)
+	ctx := WithUser(context.Background(), "user-1")
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetAppMeta(ctx); err != nil {
+			t.Fatalf("GetAppMeta failed: %v", err)
+		}
+	}
+	if backend.issued["user-1"] != 2 {
+		t.Errorf("Expected passport to be renewed before expiry, got %d passports", backend.issued["user-1"])
+	}
+
+	// 被服务端吊销的passport在401后重新获取
+	backend.revoke("user-1")
+	c.WithPassportRenewBefore(0)
+	if _, err := c.GetAppMeta(ctx); err != nil {
+		t.Fatalf("Expected request to succeed after re-authentication, got %v", err)
+	}
+	if backend.issued["user-1"] != 3 {
+		t.Errorf("Expected a new passport after 401, got %d passports", backend.issued["user-1"])
+	}
+}
+
+func TestWithAppUsesSeparatePassports(t *testing.T) {
+	var mu sync.Mutex
+	issued := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appCode := r.Header.Get("X-App-Code")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/passport" {
+			mu.Lock()
+			issued[appCode]++
+			mu.Unlock()
+			fmt.Fprintf(w, `{"access_token": "passport-%s"}`, appCode)
+			return
+		}
+		if passport := r.Header.Get("X-App-Passport"); passport != "passport-"+appCode {
+			t.Errorf("Expected passport for app %s, got %s", appCode, passport)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	app1 := NewClient(server.URL).WithAppCode("app-1")
+	app2 := app1.WithApp("app-2")
+
+	var wg sync.WaitGroup
+	for _, c := range []*Client{app1, app2, app1, app2} {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			if _, err := c.GetAppMeta(context.Background()); err != nil {
+				t.Errorf("GetAppMeta failed: %v", err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if issued["app-1"] != 1 || issued["app-2"] != 1 {
+		t.Errorf("Expected one passport per app, got %v", issued)
+	}
+}
+
+func TestWithAppCodeClearsPassports(t *testing.T) {
+	issued := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appCode := r.Header.Get("X-App-Code")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/passport" {
+			issued[appCode+"/"+r.URL.Query().Get("user_id")]++
+			fmt.Fprintf(w, `{"access_token": "passport-%s"}`, appCode)
+			return
+		}
+		if passport := r.Header.Get("X-App-Passport"); passport != "passport-"+appCode {
+			t.Errorf("Expected passport for app %s, got %s", appCode, passport)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL).WithAppCode("app-1")
+	for _, userID := range []string{"", "alice"} {
+		if _, err := c.GetAppMeta(WithUser(context.Background(), userID)); err != nil {
+			t.Fatalf("GetAppMeta failed: %v", err)
+		}
+	}
+
+	c.WithAppCode("app-2")
+	for _, userID := range []string{"", "alice"} {
+		if _, err := c.GetAppMeta(WithUser(context.Background(), userID)); err != nil {
+			t.Fatalf("GetAppMeta failed: %v", err)
+		}
+	}
+
+	if issued["app-2/"] != 1 || issued["app-2/alice"] != 1 || len(issued) != 4 {
+		t.Errorf("Expected passports to be fetched again for app-2, got %v", issued)
+	}
+}
+
+func TestPassportCacheIsBounded(t *testing.T) {
+	cache := newPassportCache()
+	cache.maxEntries = 3
+
+	cache.store("expired", testPassport("expired", 1, time.Now().Add(-time.Minute)))
+	cache.store("u1", testPassport("u1", 1, time.Now().Add(time.Hour)))
+	cache.store("u2", testPassport("u2", 1, time.Now().Add(time.Hour)))
+
+	// 缓存已满时先清理过期的passport
+	cache.entry("u3")
+	if _, ok := cache.entries["expired"]; ok || len(cache.entries) != 3 {
+		t.Errorf("Expected expired passport to be pruned, got %d entries", len(cache.entries))
+	}
+
+	// 没有过期项时淘汰最久未使用的用户
+	cache.entries["u1"].lastUsed = time.Now().Add(-time.Hour)
+	cache.entry("u4")
+	if _, ok := cache.entries["u1"]; ok || len(cache.entries) != 3 {
+		t.Errorf("Expected least recently used user to be evicted, got %d entries", len(cache.entries))
+	}
+	for _, userID := range []string{"u2", "u3", "u4"} {
+		if _, ok := cache.entries[userID]; !ok {
+			t.Errorf("Expected %s to remain cached", userID)
+		}
+	}
+}